@@ -11,6 +11,8 @@ package swarm
 import (
 	"reflect"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Unique brief summary of the message, specific to the broker
@@ -56,6 +58,10 @@ type Bag struct {
 	// I/O Context of the message, as obtained from broker
 	IOContext any
 
+	// Expiration time of the message, zero value if message never expires.
+	// Brokers map it to native message expiry where available.
+	Expires time.Time
+
 	// Message raw content
 	Object []byte
 }
@@ -79,6 +85,35 @@ func ToMsg[T any](bag Bag, object T) Msg[T] {
 // 	}
 // }
 
+// Brokers with text-only message body (e.g. AWS SQS, AWS SNS) transfer
+// binary payloads as base64, the message is marked with this encoding.
+const EncodingBase64 = "base64"
+
+// IsText checks that payload contains only characters allowed by brokers with
+// text-only message body (e.g. AWS SQS, AWS SNS):
+// #x9 | #xA | #xD | #x20 to #xD7FF | #xE000 to #xFFFD | #x10000 to #x10FFFF
+func IsText(b []byte) bool {
+	for len(b) > 0 {
+		r, n := utf8.DecodeRune(b)
+		if r == utf8.RuneError && n <= 1 {
+			return false
+		}
+
+		switch {
+		case r == 0x9 || r == 0xA || r == 0xD:
+		case r >= 0x20 && r <= 0xD7FF:
+		case r >= 0xE000 && r <= 0xFFFD:
+		case r >= 0x10000 && r <= 0x10FFFF:
+		default:
+			return false
+		}
+
+		b = b[n:]
+	}
+
+	return true
+}
+
 // TypeOf returns normalized name of the type T.
 func TypeOf[T any](category ...string) string {
 	if len(category) > 0 {
@@ -10,8 +10,8 @@ package embedded
 
 import (
 	"context"
+	"time"
 
-	"github.com/fogfish/golem/pipe"
 	"github.com/fogfish/logger/x/xlog"
 	"github.com/fogfish/opts"
 	"github.com/fogfish/swarm"
@@ -45,9 +45,73 @@ func (b *EndpointBuilder) Build() (*kernel.Kernel, error) {
 	), nil
 }
 
+// EmitterBuilder publishes messages to the named in-process topic
+type EmitterBuilder struct{ *builder[*EmitterBuilder] }
+
+func Emitter() *EmitterBuilder {
+	b := &EmitterBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EmitterBuilder) Build(topic string) (*kernel.EmitterIO, error) {
+	client, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+
+	client.topic = topicOf(topic)
+
+	return kernel.NewEmitter(client, client.config), nil
+}
+
+// ListenerBuilder subscribes to the named in-process topic. Each listener
+// has own independent queue, which receives all messages published to the topic.
+type ListenerBuilder struct {
+	*builder[*ListenerBuilder]
+	patterns []string
+}
+
+func Listener() *ListenerBuilder {
+	b := &ListenerBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+// WithCategory filters messages delivered to the listener by categories,
+// the category is either exact name or pattern (e.g. "User*", see [path.Match]).
+func (b *ListenerBuilder) WithCategory(pattern ...string) *ListenerBuilder {
+	b.patterns = append(b.patterns, pattern...)
+	return b
+}
+
+func (b *ListenerBuilder) Build(topic string) (*kernel.ListenerIO, error) {
+	client, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+
+	client.topic = topicOf(topic)
+	client.subscription = &subscription{
+		queue:    client.queue,
+		patterns: b.patterns,
+	}
+	client.topic.subscribe(client.subscription)
+
+	return kernel.NewListener(client, client.config), nil
+}
+
 type builder[T any] struct {
 	b          T
 	kernelOpts []opts.Option[swarm.Config]
+	dir        string
+
+	// semantic of the queue
+	visibilityTimeout time.Duration
+	maxReceiveCount   int
+	deadLetter        string
+	delay             time.Duration
+	batchSize         int
 }
 
 // newBuilder creates new builder for EventBridge broker configuration.
@@ -60,6 +124,7 @@ func newBuilder[T any](b T) *builder[T] {
 	return &builder[T]{
 		b:          b,
 		kernelOpts: kopts,
+		batchSize:  1,
 	}
 }
 
@@ -69,11 +134,49 @@ func (b *builder[T]) WithKernel(opts ...opts.Option[swarm.Config]) T {
 	return b.b
 }
 
+// WithPersistentDir makes broker durable, messages are persisted in the directory
+// until they are acknowledged. Un-acknowledged messages are redelivered after restart.
+func (b *builder[T]) WithPersistentDir(dir string) T {
+	b.dir = dir
+	return b.b
+}
+
+// WithVisibilityTimeout emulates AWS SQS visibility timeout. Received messages
+// are invisible for the timeout, un-acknowledged messages are redelivered
+// after it, failed messages are not redelivered immediately.
+// By default, messages are in-flight until acknowledged, failed messages are
+// redelivered immediately.
+func (b *builder[T]) WithVisibilityTimeout(timeout time.Duration) T {
+	b.visibilityTimeout = timeout
+	return b.b
+}
+
+// WithMaxReceiveCount emulates AWS SQS redrive policy. Messages received more
+// than max times are moved to the dead-letter category. Messages are discarded
+// if dead-letter category is empty.
+func (b *builder[T]) WithMaxReceiveCount(n int, deadLetter string) T {
+	b.maxReceiveCount = n
+	b.deadLetter = deadLetter
+	return b.b
+}
+
+// WithDelay emulates AWS SQS delay queue, new messages are invisible for the duration.
+func (b *builder[T]) WithDelay(delay time.Duration) T {
+	b.delay = delay
+	return b.b
+}
+
+// WithBatchSize defines max number of messages received at once.
+func (b *builder[T]) WithBatchSize(size int) T {
+	b.batchSize = max(size, 1)
+	return b.b
+}
+
 // build constructs the EventBridge client with configuration
 func (b *builder[T]) build() (*Client, error) {
 	client := &Client{
 		config: swarm.NewConfig(),
-		bags:   make(map[swarm.Digest]*swarm.Bag),
+		queue:  newQueue(),
 	}
 	client.context, client.cancel = context.WithCancel(context.Background())
 
@@ -89,7 +192,24 @@ func (b *builder[T]) build() (*Client, error) {
 }
 
 func (b *builder[T]) applyService(c *Client) error {
-	cap := max(c.config.CapOut, c.config.CapRcv)
-	c.recv, c.emit = pipe.New[*swarm.Bag](c.context, cap)
+	c.queue.visibilityTimeout = b.visibilityTimeout
+	c.queue.maxReceiveCount = b.maxReceiveCount
+	c.queue.deadLetter = b.deadLetter
+	c.queue.delay = b.delay
+	c.queue.batchSize = b.batchSize
+
+	if b.dir == "" {
+		return nil
+	}
+
+	journal, err := openJournal(b.dir)
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	// redeliver pending messages recovered from the journal
+	c.journal = journal
+	c.queue.recover(journal)
+
 	return nil
 }
@@ -24,59 +24,63 @@ type Client struct {
 	cancel  context.CancelFunc
 	once    sync.Once
 
-	// Channels + inflight buffer
-	bags map[swarm.Digest]*swarm.Bag
-	recv <-chan *swarm.Bag
-	emit chan<- *swarm.Bag
+	// Queue of messages
+	queue *queue
+
+	// Topic and subscription of the pub/sub mode, nil if broker is a queue
+	topic        *topic
+	subscription *subscription
+
+	// Persistent journal of messages, nil if broker is in-memory
+	journal *journal
 }
 
-func (cli *Client) Close() error {
-	cli.once.Do(cli.cancel)
-	return nil
+func (cli *Client) Close() (err error) {
+	cli.once.Do(func() {
+		cli.cancel()
+		if cli.subscription != nil {
+			cli.topic.unsubscribe(cli.subscription)
+		}
+		if cli.journal != nil {
+			err = cli.journal.Close()
+		}
+	})
+	return
 }
 
 func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
 	bag.Digest = swarm.Digest(guid.G(guid.Clock).String())
 
-	select {
-	case cli.emit <- &bag:
-		return nil
-	case <-ctx.Done():
-		return swarm.ErrServiceIO
+	var queue interface{ Enq(*swarm.Bag) error } = cli.queue
+	if cli.topic != nil && cli.subscription == nil {
+		queue = cli.topic
+	}
+
+	if err := queue.Enq(&bag); err != nil {
+		return swarm.ErrServiceIO.With(err)
 	}
+
+	return nil
 }
 
 func (cli *Client) Ack(ctx context.Context, digest swarm.Digest) error {
-	delete(cli.bags, digest)
+	if err := cli.queue.Ack(digest); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
 	return nil
 }
 
 func (cli *Client) Err(ctx context.Context, digest swarm.Digest, err error) error {
-	if bag, has := cli.bags[digest]; has {
-		delete(cli.bags, digest)
-
-		select {
-		case cli.emit <- bag:
-			return nil
-		case <-ctx.Done():
-			return swarm.ErrServiceIO
-		}
-
-	}
+	cli.queue.Err(digest)
 	return nil
 }
 
 func (cli *Client) Ask(ctx context.Context) ([]swarm.Bag, error) {
-	req, cancel := context.WithTimeout(context.Background(), cli.config.NetworkTimeout*2)
-	defer cancel()
-
-	select {
-	case bag := <-cli.recv:
-		cli.bags[bag.Digest] = bag
-		return []swarm.Bag{*bag}, nil
-	case <-req.Done():
-		return nil, nil
-	case <-ctx.Done():
-		return nil, swarm.ErrServiceIO
+	seq, err := cli.queue.Ask(ctx, cli.config.NetworkTimeout*2)
+	if err != nil {
+		return nil, swarm.ErrServiceIO.With(err)
 	}
+
+	return seq, nil
 }
@@ -9,6 +9,7 @@
 package embedded_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/fogfish/swarm"
 	"github.com/fogfish/swarm/broker/embedded"
 	"github.com/fogfish/swarm/emit"
+	"github.com/fogfish/swarm/kernel"
 	"github.com/fogfish/swarm/listen"
 )
 
@@ -79,4 +81,286 @@ func TestEmitter(t *testing.T) {
 		)
 	})
 
+	t.Run("Emit.Expired", func(t *testing.T) {
+		q, err := embedded.Endpoint().
+			WithKernel(swarm.WithTimeToLive(time.Nanosecond)).
+			Build()
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			select {
+			case msg := <-rcv:
+				obj = msg.Object
+				ack <- msg
+			case <-time.After(50 * time.Millisecond):
+			}
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, ""),
+		)
+	})
+
+	t.Run("Persistent.Redelivery", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// the message is received but not acknowledged before restart
+		q, err := embedded.Endpoint().WithPersistentDir(dir).Build()
+		it.Then(t).Should(it.Nil(err))
+
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, _ := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			<-rcv
+			q.Close()
+		}()
+		q.Await()
+
+		// the message is redelivered after restart
+		var obj string
+		q, err = embedded.Endpoint().WithPersistentDir(dir).Build()
+		it.Then(t).Should(it.Nil(err))
+
+		rcv, ack := listen.Typed[string](q.Listener)
+		go func() {
+			msg := <-rcv
+			obj = msg.Object
+			ack <- msg
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+
+		// the acknowledged message is not redelivered
+		obj = ""
+		q, err = embedded.Endpoint().WithPersistentDir(dir).Build()
+		it.Then(t).Should(it.Nil(err))
+
+		rcv, ack = listen.Typed[string](q.Listener)
+		go func() {
+			select {
+			case msg := <-rcv:
+				obj = msg.Object
+				ack <- msg
+			case <-time.After(50 * time.Millisecond):
+			}
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, ""),
+		)
+	})
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+
+	// low-level ports of the broker
+	ports := func(b *embedded.EndpointBuilder) (kernel.Emitter, kernel.Listener, func()) {
+		q, err := b.WithKernel(swarm.WithNetworkTimeout(5 * time.Millisecond)).Build()
+		it.Then(t).Should(it.Nil(err))
+		return q.Emitter.Emitter, q.Listener.Listener, func() { q.Close() }
+	}
+
+	t.Run("VisibilityTimeout", func(t *testing.T) {
+		emitter, listener, close := ports(embedded.Endpoint().WithVisibilityTimeout(50 * time.Millisecond))
+		defer close()
+
+		it.Then(t).Should(
+			it.Nil(emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("1")})),
+		)
+
+		t0 := time.Now()
+		a, err := listener.Ask(ctx)
+		it.Then(t).Should(it.Nil(err), it.Equal(len(a), 1))
+
+		// failed message is invisible until timeout
+		it.Then(t).Should(it.Nil(listener.Err(ctx, a[0].Digest, fmt.Errorf("fail"))))
+
+		var b []swarm.Bag
+		for len(b) == 0 {
+			b, err = listener.Ask(ctx)
+			it.Then(t).Should(it.Nil(err))
+		}
+
+		it.Then(t).Should(
+			it.Equal(b[0].Digest, a[0].Digest),
+			it.Equal(string(b[0].Object), "1"),
+			it.True(time.Since(t0) >= 50*time.Millisecond),
+			it.Nil(listener.Ack(ctx, b[0].Digest)),
+		)
+
+		// acknowledged message is not redelivered
+		time.Sleep(60 * time.Millisecond)
+		seq, err := listener.Ask(ctx)
+		it.Then(t).Should(it.Nil(err), it.Seq(seq).BeEmpty())
+	})
+
+	t.Run("MaxReceiveCount", func(t *testing.T) {
+		emitter, listener, close := ports(embedded.Endpoint().WithMaxReceiveCount(2, "dlq"))
+		defer close()
+
+		it.Then(t).Should(
+			it.Nil(emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("1")})),
+		)
+
+		for range 2 {
+			seq, err := listener.Ask(ctx)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(seq[0].Category, "test"),
+				it.Nil(listener.Err(ctx, seq[0].Digest, fmt.Errorf("fail"))),
+			)
+		}
+
+		seq, err := listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(seq[0].Category, "dlq"),
+			it.Equal(string(seq[0].Object), "1"),
+		)
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		emitter, listener, close := ports(embedded.Endpoint().WithDelay(5 * time.Millisecond))
+		defer close()
+
+		t0 := time.Now()
+		it.Then(t).Should(
+			it.Nil(emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("1")})),
+		)
+
+		seq, err := listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(seq[0].Object), "1"),
+			it.True(time.Since(t0) >= 5*time.Millisecond),
+		)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		emitter, listener, close := ports(embedded.Endpoint().WithBatchSize(2))
+		defer close()
+
+		for _, x := range []string{"1", "2", "3"} {
+			it.Then(t).Should(
+				it.Nil(emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte(x)})),
+			)
+		}
+
+		a, err := listener.Ask(ctx)
+		it.Then(t).Should(it.Nil(err))
+		b, err := listener.Ask(ctx)
+		it.Then(t).Should(it.Nil(err))
+
+		it.Then(t).Should(
+			it.Equal(len(a), 2),
+			it.Equal(string(a[0].Object), "1"),
+			it.Equal(string(a[1].Object), "2"),
+			it.Equal(len(b), 1),
+			it.Equal(string(b[0].Object), "3"),
+		)
+	})
+
+	t.Run("ConcurrentPollers", func(t *testing.T) {
+		q, err := embedded.Endpoint().
+			WithBatchSize(4).
+			WithKernel(
+				swarm.WithPollerPool(4),
+				swarm.WithPollFrequency(time.Millisecond),
+			).
+			Build()
+		it.Then(t).Should(it.Nil(err))
+
+		snd := swarm.LogDeadLetters(emit.Typed[int](q.Emitter))
+		rcv, ack := listen.Typed[int](q.Listener)
+
+		seen := map[int]int{}
+		go func() {
+			for i := range 100 {
+				snd <- i
+			}
+			for range 100 {
+				msg := <-rcv
+				seen[msg.Object]++
+				ack <- msg
+			}
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(len(seen), 100),
+		)
+		for _, n := range seen {
+			it.Then(t).Should(it.Equal(n, 1))
+		}
+	})
+}
+
+func TestTopic(t *testing.T) {
+	ctx := context.Background()
+
+	subscribe := func(b *embedded.ListenerBuilder) kernel.Listener {
+		q, err := b.WithKernel(swarm.WithNetworkTimeout(5 * time.Millisecond)).Build("test-topic")
+		it.Then(t).Should(it.Nil(err))
+		t.Cleanup(q.Close)
+		return q.Listener
+	}
+
+	all := subscribe(embedded.Listener())
+	users := subscribe(embedded.Listener().WithCategory("User*"))
+
+	q, err := embedded.Emitter().Build("test-topic")
+	it.Then(t).Should(it.Nil(err))
+	defer q.Close()
+
+	it.Then(t).Should(
+		it.Nil(q.Emitter.Enq(ctx, swarm.Bag{Category: "UserCreated", Object: []byte("1")})),
+		it.Nil(q.Emitter.Enq(ctx, swarm.Bag{Category: "Note", Object: []byte("2")})),
+	)
+
+	a, err := all.Ask(ctx)
+	it.Then(t).Should(it.Nil(err))
+	b, err := all.Ask(ctx)
+	it.Then(t).Should(it.Nil(err))
+	c, err := users.Ask(ctx)
+	it.Then(t).Should(it.Nil(err))
+	d, err := users.Ask(ctx)
+	it.Then(t).Should(it.Nil(err))
+
+	it.Then(t).Should(
+		it.Equal(a[0].Category, "UserCreated"),
+		it.Equal(b[0].Category, "Note"),
+		it.Equal(c[0].Category, "UserCreated"),
+		it.Seq(d).BeEmpty(),
+	)
+
+	// acknowledgement is independent for each subscription
+	it.Then(t).Should(
+		it.Nil(users.Ack(ctx, c[0].Digest)),
+		it.Nil(all.Err(ctx, a[0].Digest, fmt.Errorf("fail"))),
+	)
+
+	e, err := all.Ask(ctx)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(e[0].Category, "UserCreated"),
+	)
 }
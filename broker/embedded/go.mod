@@ -3,7 +3,6 @@ module github.com/fogfish/swarm/broker/embedded
 go 1.24
 
 require (
-	github.com/fogfish/golem/pipe v1.2.0
 	github.com/fogfish/guid/v2 v2.1.0
 	github.com/fogfish/it/v2 v2.2.2
 	github.com/fogfish/logger/x/xlog v0.0.1
@@ -16,6 +15,7 @@ require (
 	github.com/fogfish/faults v0.3.2 // indirect
 	github.com/fogfish/golem/hseq v1.3.0 // indirect
 	github.com/fogfish/golem/optics v0.14.0 // indirect
-	github.com/fogfish/golem/pure v0.10.1 // indirect
 	github.com/fogfish/logger/v3 v3.2.0 // indirect
 )
+
+replace github.com/fogfish/swarm => ../..
@@ -0,0 +1,274 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package embedded
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fogfish/swarm"
+)
+
+const (
+	journalFile = "journal.log"
+
+	// journal is compacted when number of acknowledged entries exceeds the limit
+	journalCompactAfter = 1024
+)
+
+const (
+	opEnq = "enq"
+	opAck = "ack"
+)
+
+// entry of the journal
+type entry struct {
+	Op       string       `json:"op"`
+	Seq      uint64       `json:"seq,omitempty"`
+	Digest   swarm.Digest `json:"digest"`
+	Category string       `json:"category,omitempty"`
+	Expires  *time.Time   `json:"expires,omitempty"`
+	Object   []byte       `json:"object,omitempty"`
+}
+
+func (e entry) bag() *swarm.Bag {
+	bag := &swarm.Bag{
+		Category: e.Category,
+		Digest:   e.Digest,
+		Object:   e.Object,
+	}
+	if e.Expires != nil {
+		bag.Expires = *e.Expires
+	}
+	return bag
+}
+
+// Append-only journal of messages, it persists messages until they are
+// acknowledged. Pending messages are recovered from the journal on restart.
+type journal struct {
+	sync.Mutex
+	path    string
+	file    *os.File
+	seq     uint64
+	acked   int
+	pending map[swarm.Digest]entry
+}
+
+func openJournal(dir string) (*journal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	j := &journal{
+		path:    filepath.Join(dir, journalFile),
+		pending: make(map[swarm.Digest]entry),
+	}
+
+	if err := j.recover(); err != nil {
+		return nil, err
+	}
+
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// reads the journal, corrupted entries and the torn tail of the journal
+// (e.g. crash during write) are skipped, the following entries are recovered.
+func (j *journal) recover() error {
+	f, err := os.Open(j.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if len(line) == 0 && err != nil {
+			return nil
+		}
+
+		var e entry
+		if exx := json.Unmarshal(line, &e); exx != nil {
+			slog.Warn("journal entry is corrupted, skipped",
+				slog.String("journal", j.path),
+				slog.Any("err", exx),
+			)
+			continue
+		}
+
+		switch e.Op {
+		case opEnq:
+			j.pending[e.Digest] = e
+			j.seq = max(j.seq, e.Seq)
+		case opAck:
+			delete(j.pending, e.Digest)
+		}
+	}
+}
+
+// rewrites the journal with pending messages only
+func (j *journal) compact() error {
+	if j.file != nil {
+		if err := j.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	tmp := j.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, e := range j.ordered() {
+		if err := writeEntry(w, e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+
+	j.file, err = os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	j.acked = 0
+	return nil
+}
+
+// pending messages in the order of enqueue
+func (j *journal) ordered() []entry {
+	seq := make([]entry, 0, len(j.pending))
+	for _, e := range j.pending {
+		seq = append(seq, e)
+	}
+	slices.SortFunc(seq, func(a, b entry) int { return cmp.Compare(a.Seq, b.Seq) })
+	return seq
+}
+
+// pending messages
+func (j *journal) Pending() []*swarm.Bag {
+	j.Lock()
+	defer j.Unlock()
+
+	seq := make([]*swarm.Bag, 0, len(j.pending))
+	for _, e := range j.ordered() {
+		seq = append(seq, e.bag())
+	}
+	return seq
+}
+
+func (j *journal) Enq(bag *swarm.Bag) error {
+	j.Lock()
+	defer j.Unlock()
+
+	j.seq++
+	e := entry{
+		Op:       opEnq,
+		Seq:      j.seq,
+		Digest:   bag.Digest,
+		Category: bag.Category,
+		Object:   bag.Object,
+	}
+	if !bag.Expires.IsZero() {
+		e.Expires = &bag.Expires
+	}
+
+	if err := j.append(e); err != nil {
+		return err
+	}
+
+	j.pending[e.Digest] = e
+	return nil
+}
+
+func (j *journal) Ack(digest swarm.Digest) error {
+	j.Lock()
+	defer j.Unlock()
+
+	if _, has := j.pending[digest]; !has {
+		return nil
+	}
+
+	if err := j.append(entry{Op: opAck, Digest: digest}); err != nil {
+		return err
+	}
+
+	delete(j.pending, digest)
+
+	j.acked++
+	if j.acked > journalCompactAfter {
+		return j.compact()
+	}
+
+	return nil
+}
+
+func (j *journal) append(e entry) error {
+	if err := writeEntry(j.file, e); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+func (j *journal) Close() error {
+	j.Lock()
+	defer j.Unlock()
+
+	return j.file.Close()
+}
+
+func writeEntry(w io.Writer, e entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,99 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package embedded
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+)
+
+func TestJournal(t *testing.T) {
+	t.Run("Recover", func(t *testing.T) {
+		dir := t.TempDir()
+
+		j, err := openJournal(dir)
+		it.Then(t).Should(it.Nil(err))
+
+		for _, digest := range []swarm.Digest{"a", "b", "c"} {
+			err := j.Enq(&swarm.Bag{Category: "test", Digest: digest, Object: []byte(digest)})
+			it.Then(t).Should(it.Nil(err))
+		}
+		it.Then(t).Should(
+			it.Nil(j.Ack("b")),
+			it.Nil(j.Close()),
+		)
+
+		j, err = openJournal(dir)
+		it.Then(t).Should(it.Nil(err))
+
+		seq := j.Pending()
+		it.Then(t).Should(
+			it.Equal(len(seq), 2),
+			it.Equal(seq[0].Digest, "a"),
+			it.Equal(seq[1].Digest, "c"),
+			it.Nil(j.Close()),
+		)
+	})
+
+	t.Run("TornTail", func(t *testing.T) {
+		dir := t.TempDir()
+
+		j, err := openJournal(dir)
+		it.Then(t).Should(it.Nil(err))
+		it.Then(t).Should(
+			it.Nil(j.Enq(&swarm.Bag{Category: "test", Digest: "a", Object: []byte("a")})),
+			it.Nil(j.Close()),
+		)
+
+		f, err := os.OpenFile(filepath.Join(dir, journalFile), os.O_WRONLY|os.O_APPEND, 0644)
+		it.Then(t).Should(it.Nil(err))
+		_, err = f.Write([]byte(`{"op":"enq","seq":2,"dig`))
+		it.Then(t).Should(it.Nil(err), it.Nil(f.Close()))
+
+		j, err = openJournal(dir)
+		it.Then(t).Should(it.Nil(err))
+
+		seq := j.Pending()
+		it.Then(t).Should(
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0].Digest, "a"),
+			it.Nil(j.Close()),
+		)
+	})
+	t.Run("Corrupted", func(t *testing.T) {
+		dir := t.TempDir()
+
+		j, err := openJournal(dir)
+		it.Then(t).Should(it.Nil(err))
+		it.Then(t).Should(
+			it.Nil(j.Enq(&swarm.Bag{Category: "test", Digest: "a", Object: []byte("a")})),
+			it.Nil(j.Close()),
+		)
+
+		f, err := os.OpenFile(filepath.Join(dir, journalFile), os.O_WRONLY|os.O_APPEND, 0644)
+		it.Then(t).Should(it.Nil(err))
+		_, err = f.Write([]byte("{\"op\":\"enq\",\"seq\":2,\"dig\n{\"op\":\"enq\",\"seq\":3,\"digest\":\"c\",\"category\":\"test\"}\n"))
+		it.Then(t).Should(it.Nil(err), it.Nil(f.Close()))
+
+		j, err = openJournal(dir)
+		it.Then(t).Should(it.Nil(err))
+
+		seq := j.Pending()
+		it.Then(t).Should(
+			it.Equal(len(seq), 2),
+			it.Equal(seq[0].Digest, "a"),
+			it.Equal(seq[1].Digest, "c"),
+			it.Nil(j.Close()),
+		)
+	})
+}
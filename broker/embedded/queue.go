@@ -0,0 +1,304 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package embedded
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/swarm"
+)
+
+// message in the queue
+type message struct {
+	seq          uint64
+	bag          *swarm.Bag
+	visibleAt    time.Time
+	receiveCount int
+}
+
+// Queue emulates semantic of AWS SQS: messages are invisible to other consumers
+// for the visibility timeout after they are received, messages are moved to
+// the dead-letter category after max receive count, messages are delayed and
+// received in batches. The queue is safe for concurrent pollers.
+type queue struct {
+	sync.Mutex
+
+	// visibility timeout of received messages, zero value disables
+	// automatic redelivery (messages are in-flight until Ack or Err).
+	visibilityTimeout time.Duration
+	// max number of receives before message is moved to dead-letter category,
+	// zero value disables dead-letter redrive.
+	maxReceiveCount int
+	deadLetter      string
+	// delay of newly enqueued messages
+	delay time.Duration
+	// max number of messages received at once
+	batchSize int
+
+	seq      uint64
+	ready    []*message // ordered by seq
+	inflight map[swarm.Digest]*message
+	notify   chan struct{}
+
+	// Persistent journal of messages, nil if queue is in-memory
+	journal *journal
+}
+
+func newQueue() *queue {
+	return &queue{
+		batchSize: 1,
+		inflight:  make(map[swarm.Digest]*message),
+		notify:    make(chan struct{}, 1),
+	}
+}
+
+// recovers pending messages from the journal
+func (q *queue) recover(journal *journal) {
+	q.Lock()
+	defer q.Unlock()
+
+	q.journal = journal
+	for _, bag := range journal.Pending() {
+		q.push(&message{bag: bag})
+	}
+}
+
+func (q *queue) Enq(bag *swarm.Bag) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.journal != nil {
+		if err := q.journal.Enq(bag); err != nil {
+			return err
+		}
+	}
+
+	q.push(&message{bag: bag, visibleAt: time.Now().Add(q.delay)})
+	return nil
+}
+
+func (q *queue) Ack(digest swarm.Digest) error {
+	q.Lock()
+	defer q.Unlock()
+
+	delete(q.inflight, digest)
+
+	// message might be returned to the queue after visibility timeout
+	q.ready = slices.DeleteFunc(q.ready,
+		func(m *message) bool { return m.bag.Digest == digest },
+	)
+
+	if q.journal != nil {
+		return q.journal.Ack(digest)
+	}
+
+	return nil
+}
+
+// Err returns message to the queue immediately if visibility timeout is disabled,
+// otherwise message is redelivered after visibility timeout (like AWS SQS does).
+func (q *queue) Err(digest swarm.Digest) {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.visibilityTimeout != 0 {
+		return
+	}
+
+	if msg, has := q.inflight[digest]; has {
+		delete(q.inflight, digest)
+		msg.visibleAt = time.Time{}
+		q.push(msg)
+	}
+}
+
+// Ask receives batch of visible messages, it waits until messages are
+// available or the timeout is expired.
+func (q *queue) Ask(ctx context.Context, timeout time.Duration) ([]swarm.Bag, error) {
+	req, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		seq, wake, err := q.receive()
+		if err != nil || len(seq) != 0 {
+			return seq, err
+		}
+
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if !wake.IsZero() {
+			timer = time.NewTimer(time.Until(wake))
+			expired = timer.C
+		}
+
+		select {
+		case <-q.notify:
+		case <-expired:
+		case <-req.Done():
+			if ctx.Err() != nil {
+				return nil, swarm.ErrServiceIO
+			}
+			return nil, nil
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// receives batch of visible messages, returns the time when next message
+// becomes visible if no messages are available.
+func (q *queue) receive() ([]swarm.Bag, time.Time, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	now := time.Now()
+	q.reclaim(now)
+
+	var wake time.Time
+	var redriven []*message
+	var failure error
+	seq := make([]swarm.Bag, 0, q.batchSize)
+	ready := q.ready[:0]
+	for i, msg := range q.ready {
+		if len(seq) == q.batchSize || failure != nil {
+			ready = append(ready, q.ready[i:]...)
+			break
+		}
+
+		if msg.visibleAt.After(now) {
+			if wake.IsZero() || msg.visibleAt.Before(wake) {
+				wake = msg.visibleAt
+			}
+			ready = append(ready, msg)
+			continue
+		}
+
+		// Note: expired messages are discarded by broker, emulating native expiry
+		if !msg.bag.Expires.IsZero() && now.After(msg.bag.Expires) {
+			if failure = q.discard(msg); failure != nil {
+				ready = append(ready, msg)
+			}
+			continue
+		}
+
+		if q.maxReceiveCount > 0 && msg.receiveCount >= q.maxReceiveCount {
+			dlq, err := q.redrive(msg)
+			switch {
+			case err != nil:
+				failure = err
+				ready = append(ready, msg)
+			case dlq != nil:
+				redriven = append(redriven, dlq)
+			}
+			continue
+		}
+
+		msg.receiveCount++
+		if q.visibilityTimeout != 0 {
+			msg.visibleAt = now.Add(q.visibilityTimeout)
+		}
+		q.inflight[msg.bag.Digest] = msg
+		seq = append(seq, *msg.bag)
+	}
+	clear(q.ready[len(ready):])
+	q.ready = ready
+
+	for _, msg := range q.inflight {
+		if q.visibilityTimeout != 0 && (wake.IsZero() || msg.visibleAt.Before(wake)) {
+			wake = msg.visibleAt
+		}
+	}
+
+	// message moved to dead-letter category is visible immediately
+	for _, msg := range redriven {
+		q.push(msg)
+		wake = now
+	}
+
+	// Note: the failure is reported only if nothing is received, it re-occurs on next receive
+	if failure != nil && len(seq) == 0 {
+		return nil, wake, failure
+	}
+
+	return seq, wake, nil
+}
+
+// returns in-flight messages to the queue after visibility timeout
+func (q *queue) reclaim(now time.Time) {
+	if q.visibilityTimeout == 0 {
+		return
+	}
+
+	for digest, msg := range q.inflight {
+		if !msg.visibleAt.After(now) {
+			delete(q.inflight, digest)
+			q.push(msg)
+		}
+	}
+}
+
+// moves message to the dead-letter category, message without dead-letter category is discarded
+func (q *queue) redrive(msg *message) (*message, error) {
+	if err := q.discard(msg); err != nil {
+		return nil, err
+	}
+
+	if q.deadLetter == "" {
+		slog.Warn("message is discarded after max receive count",
+			slog.Any("cat", msg.bag.Category),
+			slog.Any("digest", msg.bag.Digest),
+		)
+		return nil, nil
+	}
+
+	bag := *msg.bag
+	bag.Category = q.deadLetter
+	bag.Digest = swarm.Digest(guid.G(guid.Clock).String())
+
+	if q.journal != nil {
+		if err := q.journal.Enq(&bag); err != nil {
+			return nil, err
+		}
+	}
+
+	return &message{bag: &bag}, nil
+}
+
+func (q *queue) discard(msg *message) error {
+	if q.journal != nil {
+		return q.journal.Ack(msg.bag.Digest)
+	}
+	return nil
+}
+
+// pushes message into the queue, preserving the order of enqueue
+func (q *queue) push(msg *message) {
+	if msg.seq == 0 {
+		q.seq++
+		msg.seq = q.seq
+	}
+
+	at, _ := slices.BinarySearchFunc(q.ready, msg.seq,
+		func(m *message, seq uint64) int { return cmp.Compare(m.seq, seq) },
+	)
+	q.ready = slices.Insert(q.ready, at, msg)
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,99 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package embedded
+
+import (
+	"path"
+	"slices"
+	"sync"
+
+	"github.com/fogfish/swarm"
+)
+
+// in-process registry of topics
+var topics = struct {
+	sync.Mutex
+	topics map[string]*topic
+}{topics: make(map[string]*topic)}
+
+func topicOf(name string) *topic {
+	topics.Lock()
+	defer topics.Unlock()
+
+	t, has := topics.topics[name]
+	if !has {
+		t = &topic{}
+		topics.topics[name] = t
+	}
+
+	return t
+}
+
+// subscription binds the queue with the topic, the queue receives messages
+// of categories matching any of the patterns, all messages if patterns are empty.
+type subscription struct {
+	queue    *queue
+	patterns []string
+}
+
+func (s *subscription) match(cat string) bool {
+	if len(s.patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range s.patterns {
+		if ok, _ := path.Match(pattern, cat); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Topic delivers messages to each subscribed queue, it mirrors how
+// AWS EventBridge rules deliver events to different targets.
+// Messages are discarded if the topic has no matching subscriptions.
+type topic struct {
+	sync.RWMutex
+	subscriptions []*subscription
+}
+
+func (t *topic) Enq(bag *swarm.Bag) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, s := range t.subscriptions {
+		if !s.match(bag.Category) {
+			continue
+		}
+
+		msg := *bag
+		if err := s.queue.Enq(&msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *topic) subscribe(s *subscription) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.subscriptions = append(t.subscriptions, s)
+}
+
+func (t *topic) unsubscribe(s *subscription) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.subscriptions = slices.DeleteFunc(t.subscriptions,
+		func(x *subscription) bool { return x == s },
+	)
+}
@@ -9,7 +9,9 @@
 package eventbridge
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -21,6 +23,9 @@ import (
 	"github.com/fogfish/swarm/kernel"
 )
 
+// Limit of AWS EventBridge entry size, it includes source, detail type and detail.
+const MaxEntrySize = 256 * 1024
+
 // EventBridge client
 type Client struct {
 	service EventBridge
@@ -37,6 +42,16 @@ func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
 	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
 	defer cancel()
 
+	detail, err := pack(bag.Object)
+	if err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	size := len(cli.config.Agent) + len(bag.Category) + len(detail)
+	if size > MaxEntrySize {
+		return swarm.ErrEnqueue.With(swarm.ErrTooLarge(size, MaxEntrySize))
+	}
+
 	ret, err := cli.service.PutEvents(ctx,
 		&eventbridge.PutEventsInput{
 			Entries: []types.PutEventsRequestEntry{
@@ -44,7 +59,7 @@ func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
 					EventBusName: aws.String(cli.bus),
 					Source:       aws.String(cli.config.Agent),
 					DetailType:   aws.String(bag.Category),
-					Detail:       aws.String(string(bag.Object)),
+					Detail:       aws.String(string(detail)),
 				},
 			},
 		},
@@ -76,8 +91,37 @@ func (s bridge) run(ctx context.Context, evt events.CloudWatchEvent) error {
 	bag[0] = swarm.Bag{
 		Category: evt.DetailType,
 		Digest:   swarm.Digest(evt.ID),
-		Object:   evt.Detail,
+		Object:   unpack(evt.Detail),
 	}
 
 	return s.Bridge.Dispatch(ctx, bag)
 }
+
+//------------------------------------------------------------------------------
+
+// EventBridge accepts only JSON as event's detail, other payloads are
+// transferred as base64 encapsulated into JSON object.
+type octets struct {
+	Octets []byte `json:"swarm:octets"`
+}
+
+func pack(obj []byte) ([]byte, error) {
+	if json.Valid(obj) {
+		return obj, nil
+	}
+
+	return json.Marshal(octets{Octets: obj})
+}
+
+func unpack(detail []byte) []byte {
+	if !bytes.Contains(detail, []byte(`"swarm:octets"`)) {
+		return detail
+	}
+
+	var pckt map[string][]byte
+	if err := json.Unmarshal(detail, &pckt); err != nil || len(pckt) != 1 {
+		return detail
+	}
+
+	return pckt["swarm:octets"]
+}
@@ -11,7 +11,9 @@ package eventbridge
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -59,6 +61,29 @@ func TestReader(t *testing.T) {
 		)
 	})
 
+	t.Run("Dequeue.Binary", func(t *testing.T) {
+		go func() {
+			bag, _ = bridge.Ask(context.Background())
+			for _, m := range bag {
+				bridge.Ack(context.Background(), m.Digest)
+			}
+		}()
+
+		err := bridge.run(context.Background(),
+			events.CloudWatchEvent{
+				ID:         "abc-def",
+				DetailType: "category",
+				Detail:     json.RawMessage(`{"swarm:octets":"AP/+"}`),
+			},
+		)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 1),
+			it.Equiv(bag[0].Object, []byte{0x00, 0xff, 0xfe}),
+		)
+	})
+
 	t.Run("Dequeue.Timeout", func(t *testing.T) {
 		go func() {
 			bag, _ = bridge.Ask(context.Background())
@@ -139,13 +164,81 @@ func TestWriter(t *testing.T) {
 		err = q.Emitter.Enq(context.Background(),
 			swarm.Bag{
 				Category: "cat",
-				Object:   []byte(`value`),
+				Object:   []byte(`{"sut":"test"}`),
 			},
 		)
 		it.Then(t).Should(
 			it.Nil(err),
 			it.Equal(*mock.val.DetailType, "cat"),
-			it.Equal(*mock.val.Detail, "value"),
+			it.Equal(*mock.val.Detail, `{"sut":"test"}`),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.TooLarge", func(t *testing.T) {
+		mock := &mockEventBridge{}
+
+		q, err := Emitter().WithService(mock).Build("test")
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte(strings.Repeat("x", MaxEntrySize+1)),
+			},
+		)
+
+		var e interface {
+			Size() int
+			Limit() int
+		}
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Less(e.Limit(), e.Size()),
+			it.Equal(e.Limit(), MaxEntrySize),
+			it.True(mock.val.Detail == nil),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.Binary", func(t *testing.T) {
+		mock := &mockEventBridge{}
+
+		q, err := Emitter().WithService(mock).Build("test")
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte{0x00, 0xff, 0xfe},
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.val.Detail, `{"swarm:octets":"AP/+"}`),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.Protobuf", func(t *testing.T) {
+		mock := &mockEventBridge{}
+
+		q, err := Emitter().WithService(mock).Build("test")
+		it.Then(t).Should(it.Nil(err))
+
+		// protobuf StringValue{"abc"}, it is valid UTF-8 but not JSON
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte("\n\x03abc"),
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.val.Detail, `{"swarm:octets":"CgNhYmM="}`),
 		)
 
 		q.Close()
@@ -44,8 +44,10 @@ require (
 	github.com/fogfish/golem/optics v0.14.0 // indirect
 	github.com/fogfish/guid/v2 v2.1.0 // indirect
 	github.com/fogfish/logger/v3 v3.2.0 // indirect
+	github.com/google/go-cmp v0.7.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/stretchr/testify v1.11.1 // indirect
 	github.com/yuin/goldmark v1.7.12 // indirect
 	golang.org/x/lint v0.0.0-20241112194109-818c5a804067 // indirect
 	golang.org/x/mod v0.26.0 // indirect
@@ -53,3 +55,5 @@ require (
 	golang.org/x/sys v0.34.0 // indirect
 	golang.org/x/tools v0.35.0 // indirect
 )
+
+replace github.com/fogfish/swarm => ../..
@@ -10,6 +10,7 @@ package eventsqs
 
 import (
 	"context"
+	"encoding/base64"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -35,6 +36,11 @@ func (s bridge) run(ctx context.Context, events events.SQSEvent) error {
 			Digest:   swarm.Digest(evt.ReceiptHandle),
 			Object:   []byte(evt.Body),
 		}
+
+		// SQS message body is text, binary payloads are transferred as base64.
+		if attr(&evt, "Encoding") == swarm.EncodingBase64 {
+			bag[i].Object, bag[i].Error = base64.StdEncoding.DecodeString(evt.Body)
+		}
 	}
 
 	return s.Bridge.Dispatch(ctx, bag)
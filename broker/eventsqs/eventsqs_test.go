@@ -112,6 +112,37 @@ func TestReader(t *testing.T) {
 		)
 	})
 
+	t.Run("Dequeue.Binary", func(t *testing.T) {
+		go func() {
+			bag, _ = bridge.Ask(context.Background())
+			for _, m := range bag {
+				bridge.Ack(context.Background(), m.Digest)
+			}
+		}()
+
+		err := bridge.run(context.Background(),
+			events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						MessageId:     "abc-def",
+						ReceiptHandle: "receipt",
+						Body:          `AP/+`,
+						MessageAttributes: map[string]events.SQSMessageAttribute{
+							"Category": {StringValue: aws.String("cat")},
+							"Encoding": {StringValue: aws.String("base64")},
+						},
+					},
+				},
+			},
+		)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 1),
+			it.Equiv(bag[0].Object, []byte{0x00, 0xff, 0xfe}),
+		)
+	})
+
 	t.Run("Dequeue.Timeout", func(t *testing.T) {
 		go func() {
 			bag, _ = bridge.Ask(context.Background())
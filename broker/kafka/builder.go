@@ -0,0 +1,229 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kafka
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/fogfish/logger/x/xlog"
+	"github.com/fogfish/opts"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Environment define name of source Kafka topic
+const EnvConfigSourceKafka = "CONFIG_SWARM_SOURCE_KAFKA"
+
+// Environment define name of target Kafka topic
+const EnvConfigTargetKafka = "CONFIG_SWARM_TARGET_KAFKA"
+
+func Must[T any](v T, err error) T {
+	if err != nil {
+		xlog.Emergency("kafka broker has failed", err)
+	}
+	return v
+}
+
+type EndpointBuilder struct{ *builder[*EndpointBuilder] }
+
+func Endpoint() *EndpointBuilder {
+	b := &EndpointBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EndpointBuilder) Build(topic string) (*kernel.Kernel, error) {
+	client, err := b.build(topic, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return kernel.New(
+		kernel.NewEmitter(client, client.config),
+		kernel.NewListener(client, client.config),
+	), nil
+}
+
+type EmitterBuilder struct{ *builder[*EmitterBuilder] }
+
+func Emitter() *EmitterBuilder {
+	b := &EmitterBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EmitterBuilder) Build(topic string) (*kernel.EmitterIO, error) {
+	client, err := b.build(topic, false)
+	if err != nil {
+		return nil, err
+	}
+	return kernel.NewEmitter(client, client.config), nil
+}
+
+type ListenerBuilder struct{ *builder[*ListenerBuilder] }
+
+func Listener() *ListenerBuilder {
+	b := &ListenerBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *ListenerBuilder) Build(topic string) (*kernel.ListenerIO, error) {
+	client, err := b.build(topic, true)
+	if err != nil {
+		return nil, err
+	}
+	return kernel.NewListener(client, client.config), nil
+}
+
+//------------------------------------------------------------------------------
+
+type builder[T any] struct {
+	b                T
+	kernelOpts       []opts.Option[swarm.Config]
+	kafkaOpts        []kgo.Opt
+	seeds            []string
+	group            string
+	topicPerCategory bool
+	key              func(swarm.Bag) []byte
+	batchSize        int
+	askWaitTime      time.Duration
+}
+
+// newBuilder creates new builder for Kafka broker configuration.
+func newBuilder[T any](b T) *builder[T] {
+	kopts := []opts.Option[swarm.Config]{
+		swarm.WithLogStdErr(),
+		swarm.WithConfigFromEnv(),
+	}
+
+	return &builder[T]{
+		b:           b,
+		kernelOpts:  kopts,
+		seeds:       []string{"localhost:9092"},
+		batchSize:   1,
+		askWaitTime: 5 * time.Second,
+	}
+}
+
+// WithKernel configures swarm kernel options for advanced usage.
+func (b *builder[T]) WithKernel(opts ...opts.Option[swarm.Config]) T {
+	b.kernelOpts = append(b.kernelOpts, opts...)
+	return b.b
+}
+
+// WithKafka configures Kafka client options (e.g. TLS, SASL) for advanced usage.
+func (b *builder[T]) WithKafka(opts ...kgo.Opt) T {
+	b.kafkaOpts = append(b.kafkaOpts, opts...)
+	return b.b
+}
+
+// WithSeeds configures seed brokers of Kafka cluster.
+func (b *builder[T]) WithSeeds(seeds ...string) T {
+	b.seeds = seeds
+	return b.b
+}
+
+// WithGroup configures name of consumer group, the group shares partitions
+// among all listeners with same name. Default is the agent name.
+func (b *builder[T]) WithGroup(name string) T {
+	b.group = name
+	return b.b
+}
+
+// WithTopicPerCategory routes each category to own topic "topic.category",
+// the listener consumes all topics of the prefix. By default, all categories
+// share the single topic and the category is routed using record header.
+func (b *builder[T]) WithTopicPerCategory() T {
+	b.topicPerCategory = true
+	return b.b
+}
+
+// WithPartitionKey configures the key of produced records, records with
+// same key are written to same partition, preserving their order.
+func (b *builder[T]) WithPartitionKey(key func(swarm.Bag) []byte) T {
+	b.key = key
+	return b.b
+}
+
+// WithBatchSize configures number of records polled at once.
+func (b *builder[T]) WithBatchSize(size int) T {
+	b.batchSize = max(size, 1)
+	return b.b
+}
+
+// WithWaitTime configures max time to wait for records.
+func (b *builder[T]) WithWaitTime(duration time.Duration) T {
+	b.askWaitTime = duration
+	return b.b
+}
+
+// build constructs the Kafka client with configuration
+func (b *builder[T]) build(topic string, consumer bool) (*Client, error) {
+	client := &Client{
+		config:           swarm.NewConfig(),
+		topic:            topic,
+		topicPerCategory: b.topicPerCategory,
+		key:              b.key,
+		batchSize:        b.batchSize,
+		askWaitTime:      b.askWaitTime,
+		inflight:         make(map[swarm.Digest]*kgo.Record),
+		offsets:          make(offsets),
+	}
+
+	if err := opts.Apply(&client.config, b.kernelOpts); err != nil {
+		return nil, err
+	}
+
+	if err := b.applyService(client, consumer); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (b *builder[T]) applyService(c *Client, consumer bool) error {
+	kopts := []kgo.Opt{
+		kgo.SeedBrokers(b.seeds...),
+		kgo.DialTimeout(c.config.NetworkTimeout),
+	}
+
+	if consumer {
+		group := b.group
+		if group == "" {
+			group = c.config.Agent
+		}
+
+		kopts = append(kopts,
+			kgo.ConsumerGroup(group),
+			kgo.DisableAutoCommit(),
+			kgo.OnPartitionsRevoked(c.revoke),
+			kgo.OnPartitionsLost(c.revoke),
+		)
+
+		if b.topicPerCategory {
+			kopts = append(kopts,
+				kgo.ConsumeTopics("^"+regexp.QuoteMeta(c.topic)+`\..+`),
+				kgo.ConsumeRegex(),
+			)
+		} else {
+			kopts = append(kopts, kgo.ConsumeTopics(c.topic))
+		}
+	}
+
+	service, err := kgo.NewClient(append(kopts, b.kafkaOpts...)...)
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+	c.service = service
+
+	return nil
+}
@@ -0,0 +1,27 @@
+module github.com/fogfish/swarm/broker/kafka
+
+go 1.24.0
+
+require (
+	github.com/fogfish/it/v2 v2.2.2
+	github.com/fogfish/logger/x/xlog v0.0.1
+	github.com/fogfish/opts v0.0.5
+	github.com/fogfish/swarm v0.24.1
+	github.com/twmb/franz-go v1.20.7
+	github.com/twmb/franz-go/pkg/kfake v0.0.0-20260218082530-ae75cacb982c
+	github.com/twmb/franz-go/pkg/kmsg v1.14.0
+)
+
+require (
+	github.com/fogfish/curie/v2 v2.1.2 // indirect
+	github.com/fogfish/faults v0.3.2 // indirect
+	github.com/fogfish/golem/hseq v1.3.0 // indirect
+	github.com/fogfish/golem/optics v0.14.0 // indirect
+	github.com/fogfish/guid/v2 v2.1.0 // indirect
+	github.com/fogfish/logger/v3 v3.2.0 // indirect
+	github.com/klauspost/compress v1.19.2 // indirect
+	github.com/pierrec/lz4/v4 v4.1.30 // indirect
+	golang.org/x/crypto v0.48.0 // indirect
+)
+
+replace github.com/fogfish/swarm => ../..
@@ -0,0 +1,252 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fogfish/swarm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// Record headers used by the broker
+const (
+	HeaderCategory = "Swarm-Category"
+	HeaderSource   = "Swarm-Source"
+)
+
+type Client struct {
+	config  swarm.Config
+	service *kgo.Client
+	topic   string
+	// topic of category is "topic.category" if enabled
+	topicPerCategory bool
+	key              func(swarm.Bag) []byte
+	batchSize        int
+	askWaitTime      time.Duration
+	// endpoint shares the client among emitter and listener
+	closer sync.Once
+
+	// in-flight records, awaiting acknowledgement
+	sync.Mutex
+	inflight map[swarm.Digest]*kgo.Record
+	offsets  offsets
+
+	// Note: commits are serialized, otherwise concurrent commit might rewind the offset
+	commit sync.Mutex
+}
+
+func (cli *Client) Close() error {
+	cli.closer.Do(cli.service.Close)
+	return nil
+}
+
+// Enq enqueues message to broker
+func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	rec := &kgo.Record{
+		Topic: cli.topicOf(bag.Category),
+		Value: bag.Object,
+		Headers: []kgo.RecordHeader{
+			{Key: HeaderCategory, Value: []byte(bag.Category)},
+			{Key: HeaderSource, Value: []byte(cli.config.Agent)},
+		},
+	}
+	if cli.key != nil {
+		rec.Key = cli.key(bag)
+	}
+
+	if err := cli.service.ProduceSync(ctx, rec).FirstErr(); err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	return nil
+}
+
+// Ack commits the offset of the message once all preceding messages
+// in the partition are acknowledged.
+func (cli *Client) Ack(ctx context.Context, digest swarm.Digest) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	cli.commit.Lock()
+	defer cli.commit.Unlock()
+
+	rec, offset, has := cli.ack(digest)
+	if !has {
+		return nil
+	}
+
+	var err error
+	cli.service.CommitOffsetsSync(ctx,
+		map[string]map[int32]kgo.EpochOffset{
+			rec.Topic: {rec.Partition: offset},
+		},
+		func(_ *kgo.Client, _ *kmsg.OffsetCommitRequest, resp *kmsg.OffsetCommitResponse, e error) {
+			err = e
+			if err != nil {
+				return
+			}
+
+			for _, t := range resp.Topics {
+				for _, p := range t.Partitions {
+					if e := kerr.ErrorForCode(p.ErrorCode); e != nil {
+						err = e
+						return
+					}
+				}
+			}
+		},
+	)
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return nil
+}
+
+func (cli *Client) ack(digest swarm.Digest) (*kgo.Record, kgo.EpochOffset, bool) {
+	cli.Lock()
+	defer cli.Unlock()
+
+	rec, has := cli.inflight[digest]
+	if !has {
+		return nil, kgo.EpochOffset{}, false
+	}
+	delete(cli.inflight, digest)
+
+	offset, has := cli.offsets.ack(rec)
+	return rec, offset, has
+}
+
+// Err re-publishes the message to the end of its topic and acknowledges
+// the original one. Kafka has no negative acknowledgement, the consumer
+// would have to rewind the partition to re-deliver the message otherwise.
+func (cli *Client) Err(ctx context.Context, digest swarm.Digest, err error) error {
+	cli.Lock()
+	rec, has := cli.inflight[digest]
+	cli.Unlock()
+
+	if !has {
+		return nil
+	}
+
+	retry := &kgo.Record{
+		Topic:   rec.Topic,
+		Key:     rec.Key,
+		Value:   rec.Value,
+		Headers: rec.Headers,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	if err := cli.service.ProduceSync(ctx, retry).FirstErr(); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return cli.Ack(ctx, digest)
+}
+
+// Ask polls messages from topics assigned to the consumer group member
+func (cli *Client) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	ctx, cancel := context.WithTimeout(ctx, cli.askWaitTime)
+	defer cancel()
+
+	fetches := cli.service.PollRecords(ctx, cli.batchSize)
+	if fetches.IsClientClosed() {
+		return nil, nil
+	}
+
+	cli.Lock()
+	seq := make([]swarm.Bag, 0, cli.batchSize)
+	fetches.EachRecord(func(rec *kgo.Record) {
+		digest := swarm.Digest(fmt.Sprintf("%s/%d/%d", rec.Topic, rec.Partition, rec.Offset))
+
+		cli.inflight[digest] = rec
+		cli.offsets.track(rec)
+
+		seq = append(seq, swarm.Bag{
+			Category: header(rec, HeaderCategory),
+			Digest:   digest,
+			Object:   rec.Value,
+		})
+	})
+	cli.Unlock()
+
+	if len(seq) != 0 {
+		return seq, nil
+	}
+
+	for _, e := range fetches.Errors() {
+		if !errors.Is(e.Err, context.DeadlineExceeded) && !errors.Is(e.Err, context.Canceled) {
+			return nil, swarm.ErrDequeue.With(e.Err)
+		}
+	}
+
+	return nil, nil
+}
+
+// revoke drops in-flight records of partitions re-assigned to other members
+// of the group, the records are re-delivered to the new owner.
+func (cli *Client) revoke(_ context.Context, _ *kgo.Client, topics map[string][]int32) {
+	cli.Lock()
+	defer cli.Unlock()
+
+	cli.offsets.revoke(topics)
+	for digest, rec := range cli.inflight {
+		if _, has := cli.offsets[topicPartition{rec.Topic, rec.Partition}]; !has {
+			delete(cli.inflight, digest)
+		}
+	}
+}
+
+func (cli *Client) topicOf(category string) string {
+	if !cli.topicPerCategory {
+		return cli.topic
+	}
+
+	return cli.topic + "." + token(category)
+}
+
+func header(rec *kgo.Record, key string) string {
+	for _, h := range rec.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Kafka allows only ASCII alphanumerics, '.', '_' and '-' in topic names,
+// the category is sanitized to the legal topic name.
+func token(name string) string {
+	return strings.Map(
+		func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+				return r
+			case r == '.', r == '_', r == '-':
+				return r
+			default:
+				return '_'
+			}
+		},
+		name,
+	)
+}
@@ -0,0 +1,206 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kafka_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/broker/kafka"
+	"github.com/fogfish/swarm/emit"
+	"github.com/fogfish/swarm/listen"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestBroker(t *testing.T) {
+	seeds := runCluster(t)
+
+	t.Run("Emit.Recv", func(t *testing.T) {
+		q, err := kafka.Endpoint().WithSeeds(seeds...).Build("test-emit-recv")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg := <-rcv
+			obj = msg.Object
+			ack <- msg
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Emit.Error.Recv", func(t *testing.T) {
+		q, err := kafka.Endpoint().WithSeeds(seeds...).Build("test-emit-err")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg1 := <-rcv
+			ack <- msg1.Fail(fmt.Errorf("fail"))
+
+			msg2 := <-rcv
+			obj = msg2.Object
+			ack <- msg2
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("TopicPerCategory", func(t *testing.T) {
+		ctx := context.Background()
+		q, err := kafka.Emitter().
+			WithSeeds(seeds...).
+			WithKafka(kgo.AllowAutoTopicCreation()).
+			WithTopicPerCategory().
+			Build("test")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Enq(ctx, swarm.Bag{Category: "example.com/User", Object: []byte("user")})
+		it.Then(t).Should(it.Nil(err))
+
+		r, err := kafka.Listener().WithSeeds(seeds...).WithTopicPerCategory().Build("test")
+		it.Then(t).Should(it.Nil(err))
+		defer r.Close()
+
+		seq, err := r.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0].Category, "example.com/User"),
+			it.True(strings.HasPrefix(string(seq[0].Digest), "test.example.com_User/")),
+			it.Equal(string(seq[0].Object), "user"),
+			it.Nil(r.Listener.Ack(ctx, seq[0].Digest)),
+		)
+	})
+
+	t.Run("PartitionKey", func(t *testing.T) {
+		ctx := context.Background()
+		q, err := kafka.Endpoint().
+			WithSeeds(seeds...).
+			WithPartitionKey(func(bag swarm.Bag) []byte { return []byte(bag.Category) }).
+			WithBatchSize(10).
+			Build("test-keyed")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		for i := range 5 {
+			err := q.Emitter.Emitter.Enq(ctx, swarm.Bag{Category: "user", Object: fmt.Appendf(nil, "%d", i)})
+			it.Then(t).Should(it.Nil(err))
+		}
+
+		var seq []swarm.Bag
+		for len(seq) < 5 {
+			bag, err := q.Listener.Listener.Ask(ctx)
+			it.Then(t).Should(it.Nil(err))
+			seq = append(seq, bag...)
+		}
+
+		partition := func(digest swarm.Digest) string {
+			return strings.Split(string(digest), "/")[1]
+		}
+
+		for i, bag := range seq {
+			it.Then(t).Should(
+				it.Equal(string(bag.Object), fmt.Sprintf("%d", i)),
+				it.Equal(partition(bag.Digest), partition(seq[0].Digest)),
+			)
+		}
+	})
+
+	t.Run("Ack.OutOfOrder", func(t *testing.T) {
+		ctx := context.Background()
+		listener := func() *kafka.ListenerBuilder {
+			return kafka.Listener().WithSeeds(seeds...).WithGroup("test").WithBatchSize(3)
+		}
+
+		q, err := kafka.Emitter().WithSeeds(seeds...).Build("test-ack")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		for _, x := range []string{"a", "b", "c"} {
+			err := q.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte(x)})
+			it.Then(t).Should(it.Nil(err))
+		}
+
+		a, err := listener().Build("test-ack")
+		it.Then(t).Should(it.Nil(err))
+
+		var seq []swarm.Bag
+		for len(seq) < 3 {
+			bag, err := a.Listener.Ask(ctx)
+			it.Then(t).Should(it.Nil(err))
+			seq = append(seq, bag...)
+		}
+
+		// "b" is not acknowledged, the committed offset stays at "b"
+		it.Then(t).Should(
+			it.Nil(a.Listener.Ack(ctx, seq[2].Digest)),
+			it.Nil(a.Listener.Ack(ctx, seq[0].Digest)),
+		)
+		a.Close()
+
+		b, err := listener().Build("test-ack")
+		it.Then(t).Should(it.Nil(err))
+		defer b.Close()
+
+		seq = seq[:0]
+		for len(seq) < 2 {
+			bag, err := b.Listener.Ask(ctx)
+			it.Then(t).Should(it.Nil(err))
+			seq = append(seq, bag...)
+		}
+
+		it.Then(t).Should(
+			it.Equal(string(seq[0].Object), "b"),
+			it.Equal(string(seq[1].Object), "c"),
+		)
+	})
+}
+
+func runCluster(t *testing.T) []string {
+	t.Helper()
+
+	cluster, err := kfake.NewCluster(
+		kfake.NumBrokers(1),
+		kfake.SeedTopics(1, "test-emit-recv", "test-emit-err", "test-ack"),
+		kfake.SeedTopics(4, "test-keyed"),
+		kfake.AllowAutoTopicCreation(),
+	)
+	it.Then(t).Should(it.Nil(err))
+	t.Cleanup(cluster.Close)
+
+	return cluster.ListenAddrs()
+}
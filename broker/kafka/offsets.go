@@ -0,0 +1,82 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kafka
+
+import (
+	"slices"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// offsets of the partition received by the consumer
+type partition struct {
+	// received but not committed offsets, in the order of receive
+	pending []int64
+	// acknowledged offsets, waiting for preceding ones
+	acked map[int64]struct{}
+	epoch int32
+}
+
+// Kafka commits the position within the partition, the message is committed
+// only after all preceding messages are acknowledged. Out-of-order acks are
+// tracked until the gap is closed.
+type offsets map[topicPartition]*partition
+
+func (o offsets) track(rec *kgo.Record) {
+	tp := topicPartition{rec.Topic, rec.Partition}
+	p, has := o[tp]
+	if !has {
+		p = &partition{acked: make(map[int64]struct{})}
+		o[tp] = p
+	}
+
+	p.epoch = rec.LeaderEpoch
+	p.pending = append(p.pending, rec.Offset)
+}
+
+// acknowledges the record, returns the offset to commit if any
+func (o offsets) ack(rec *kgo.Record) (kgo.EpochOffset, bool) {
+	p, has := o[topicPartition{rec.Topic, rec.Partition}]
+	if !has || !slices.Contains(p.pending, rec.Offset) {
+		return kgo.EpochOffset{}, false
+	}
+
+	p.acked[rec.Offset] = struct{}{}
+
+	commit := int64(-1)
+	for len(p.pending) > 0 {
+		if _, has := p.acked[p.pending[0]]; !has {
+			break
+		}
+		delete(p.acked, p.pending[0])
+		commit = p.pending[0] + 1
+		p.pending = p.pending[1:]
+	}
+
+	if commit == -1 {
+		return kgo.EpochOffset{}, false
+	}
+
+	// Note: committed offset is the position of next message to consume
+	return kgo.EpochOffset{Epoch: p.epoch, Offset: commit}, true
+}
+
+// drops offsets of partitions that are not owned by consumer anymore
+func (o offsets) revoke(topics map[string][]int32) {
+	for topic, partitions := range topics {
+		for _, partition := range partitions {
+			delete(o, topicPartition{topic, partition})
+		}
+	}
+}
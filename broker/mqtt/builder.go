@@ -0,0 +1,240 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package mqtt
+
+import (
+	"context"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/logger/x/xlog"
+	"github.com/fogfish/opts"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+)
+
+// Environment define name of source MQTT root topic
+const EnvConfigSourceMQTT = "CONFIG_SWARM_SOURCE_MQTT"
+
+// Environment define name of target MQTT root topic
+const EnvConfigTargetMQTT = "CONFIG_SWARM_TARGET_MQTT"
+
+func Must[T any](v T, err error) T {
+	if err != nil {
+		xlog.Emergency("mqtt broker has failed", err)
+	}
+	return v
+}
+
+type EndpointBuilder struct{ *builder[*EndpointBuilder] }
+
+func Endpoint() *EndpointBuilder {
+	b := &EndpointBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EndpointBuilder) Build(root string) (*kernel.Kernel, error) {
+	client, err := b.build(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.subscribe(); err != nil {
+		return nil, err
+	}
+
+	return kernel.New(
+		kernel.NewEmitter(client, client.config),
+		kernel.NewListener(client, client.config),
+	), nil
+}
+
+type EmitterBuilder struct{ *builder[*EmitterBuilder] }
+
+func Emitter() *EmitterBuilder {
+	b := &EmitterBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EmitterBuilder) Build(root string) (*kernel.EmitterIO, error) {
+	client, err := b.build(root)
+	if err != nil {
+		return nil, err
+	}
+	return kernel.NewEmitter(client, client.config), nil
+}
+
+type ListenerBuilder struct{ *builder[*ListenerBuilder] }
+
+func Listener() *ListenerBuilder {
+	b := &ListenerBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *ListenerBuilder) Build(root string) (*kernel.ListenerIO, error) {
+	client, err := b.build(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.subscribe(); err != nil {
+		return nil, err
+	}
+
+	return kernel.NewListener(client, client.config), nil
+}
+
+//------------------------------------------------------------------------------
+
+type builder[T any] struct {
+	b           T
+	kernelOpts  []opts.Option[swarm.Config]
+	clientOpts  *paho.ClientOptions
+	url         string
+	clientID    string
+	routes      []Route
+	batchSize   int
+	bufferSize  int
+	askWaitTime time.Duration
+}
+
+// newBuilder creates new builder for MQTT broker configuration.
+func newBuilder[T any](b T) *builder[T] {
+	kopts := []opts.Option[swarm.Config]{
+		swarm.WithLogStdErr(),
+		swarm.WithConfigFromEnv(),
+	}
+
+	return &builder[T]{
+		b:           b,
+		kernelOpts:  kopts,
+		url:         "tcp://localhost:1883",
+		batchSize:   1,
+		bufferSize:  1024,
+		askWaitTime: 5 * time.Second,
+	}
+}
+
+// WithKernel configures swarm kernel options for advanced usage.
+func (b *builder[T]) WithKernel(opts ...opts.Option[swarm.Config]) T {
+	b.kernelOpts = append(b.kernelOpts, opts...)
+	return b.b
+}
+
+// WithClientOptions configures MQTT client options (e.g. TLS, credentials)
+// for advanced usage. The broker overrides the message handling options.
+func (b *builder[T]) WithClientOptions(opts *paho.ClientOptions) T {
+	b.clientOpts = opts
+	return b.b
+}
+
+// WithURL configures MQTT server url.
+func (b *builder[T]) WithURL(url string) T {
+	b.url = url
+	return b.b
+}
+
+// WithClientID configures the persistent session, the server keeps
+// subscriptions and redelivers unacknowledged messages when the client
+// with same id reconnects. Default is the clean session with unique id.
+func (b *builder[T]) WithClientID(id string) T {
+	b.clientID = id
+	return b.b
+}
+
+// WithCategory routes messages of topics matching the filter (e.g. "sensor/+/temp")
+// to the category. The listener subscribes to all topics under the root otherwise,
+// the category is the topic relative to the root. Note: the server delivers
+// the message once for each matching filter if filters overlap.
+func (b *builder[T]) WithCategory(category, filter string) T {
+	b.routes = append(b.routes, Route{Category: category, Filter: filter})
+	return b.b
+}
+
+// WithBatchSize configures number of messages received at once.
+func (b *builder[T]) WithBatchSize(size int) T {
+	b.batchSize = max(size, 1)
+	return b.b
+}
+
+// WithBufferSize configures number of received messages buffered by
+// the broker before they are dequeued. The delivery of messages from
+// the server is blocked when the buffer is full. Note: the client keeps
+// the connection alive in the network loop, the buffer shall be large enough
+// to absorb messages while the application is busy.
+func (b *builder[T]) WithBufferSize(size int) T {
+	b.bufferSize = max(size, 1)
+	return b.b
+}
+
+// WithWaitTime configures max time to wait for messages.
+func (b *builder[T]) WithWaitTime(duration time.Duration) T {
+	b.askWaitTime = duration
+	return b.b
+}
+
+// build constructs the MQTT client with configuration
+func (b *builder[T]) build(root string) (*Client, error) {
+	client := &Client{
+		config:      swarm.NewConfig(),
+		root:        root,
+		routes:      b.routes,
+		batchSize:   b.batchSize,
+		askWaitTime: b.askWaitTime,
+		inflight:    make(map[swarm.Digest]paho.Message),
+		ready:       make(chan paho.Message, b.bufferSize),
+		closed:      make(chan struct{}),
+	}
+
+	if err := opts.Apply(&client.config, b.kernelOpts); err != nil {
+		return nil, err
+	}
+
+	if err := b.applyService(client); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (b *builder[T]) applyService(c *Client) error {
+	opts := b.clientOpts
+	if opts == nil {
+		opts = paho.NewClientOptions().AddBroker(b.url)
+	}
+
+	id := b.clientID
+	if id == "" {
+		id = guid.G(guid.Clock).String()
+	}
+
+	opts.
+		SetClientID(id).
+		SetCleanSession(b.clientID == "").
+		SetConnectTimeout(c.config.NetworkTimeout).
+		SetAutoAckDisabled(true).
+		SetOrderMatters(true).
+		SetDefaultPublishHandler(c.recv).
+		SetOnConnectHandler(c.resubscribe)
+
+	c.service = paho.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.NetworkTimeout)
+	defer cancel()
+
+	if err := await(ctx, c.service.Connect()); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return nil
+}
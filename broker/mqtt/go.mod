@@ -0,0 +1,29 @@
+module github.com/fogfish/swarm/broker/mqtt
+
+go 1.24.0
+
+require (
+	github.com/eclipse/paho.mqtt.golang v1.5.1
+	github.com/fogfish/guid/v2 v2.1.0
+	github.com/fogfish/it/v2 v2.2.2
+	github.com/fogfish/logger/x/xlog v0.0.1
+	github.com/fogfish/opts v0.0.5
+	github.com/fogfish/swarm v0.24.1
+	github.com/mochi-mqtt/server/v2 v2.7.9
+)
+
+require (
+	github.com/fogfish/curie/v2 v2.1.2 // indirect
+	github.com/fogfish/faults v0.3.2 // indirect
+	github.com/fogfish/golem/hseq v1.3.0 // indirect
+	github.com/fogfish/golem/optics v0.14.0 // indirect
+	github.com/fogfish/logger/v3 v3.2.0 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/rs/xid v1.4.0 // indirect
+	github.com/stretchr/testify v1.11.1 // indirect
+	golang.org/x/net v0.44.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/fogfish/swarm => ../..
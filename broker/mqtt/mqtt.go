@@ -0,0 +1,275 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package mqtt
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/fogfish/swarm"
+)
+
+// At least once delivery
+const qos = 1
+
+// Route binds MQTT topic filter with the category, messages received on
+// topics matching the filter are delivered to the category.
+type Route struct {
+	Category string
+	Filter   string
+}
+
+type Client struct {
+	config      swarm.Config
+	service     paho.Client
+	root        string
+	routes      []Route
+	batchSize   int
+	askWaitTime time.Duration
+	subscribed  atomic.Bool
+
+	// received messages, the broker buffers them for Ask
+	ready  chan paho.Message
+	closed chan struct{}
+	once   sync.Once
+
+	// in-flight messages, awaiting acknowledgement
+	sync.Mutex
+	seq      uint64
+	inflight map[swarm.Digest]paho.Message
+}
+
+func (cli *Client) Close() error {
+	cli.once.Do(func() {
+		close(cli.closed)
+		cli.service.Disconnect(uint(cli.config.NetworkTimeout.Milliseconds()))
+	})
+	return nil
+}
+
+// Enq publishes message to the topic of category, it waits for PUBACK.
+func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
+	return cli.publish(ctx, topicOf(cli.root, bag.Category), bag.Object)
+}
+
+func (cli *Client) publish(ctx context.Context, topic string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	if err := await(ctx, cli.service.Publish(topic, qos, false, payload)); err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	return nil
+}
+
+// subscribes to topic filters of routes, to all topics under the root otherwise
+func (cli *Client) subscribe() error {
+	ctx, cancel := context.WithTimeout(context.Background(), cli.config.NetworkTimeout)
+	defer cancel()
+
+	filters := map[string]byte{}
+	for _, r := range cli.routes {
+		filters[filterOf(cli.root, r.Filter)] = qos
+	}
+	if len(filters) == 0 {
+		filters[filterOf(cli.root, "#")] = qos
+	}
+
+	if err := await(ctx, cli.service.SubscribeMultiple(filters, cli.recv)); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	cli.subscribed.Store(true)
+	return nil
+}
+
+// the server drops subscriptions of clean session when connection is lost
+func (cli *Client) resubscribe(paho.Client) {
+	if !cli.subscribed.Load() {
+		return
+	}
+
+	if err := cli.subscribe(); err != nil {
+		slog.Error("mqtt subscription has failed", slog.Any("err", err))
+	}
+}
+
+// Ack sends PUBACK to the server, the server does not redeliver
+// the message anymore.
+func (cli *Client) Ack(ctx context.Context, digest swarm.Digest) error {
+	msg, has := cli.dequeue(digest)
+	if !has {
+		return nil
+	}
+
+	msg.Ack()
+	return nil
+}
+
+// Err re-publishes the message to its topic and acknowledges the original one.
+// MQTT has no negative acknowledgement, the server redelivers unacknowledged
+// messages only when the session is resumed. Note: the message is re-published
+// infinitely, MQTT 3.1.1 has no message properties to count delivery attempts.
+// Use swarm.WithMaxEventAge or dead-letter the message within application.
+func (cli *Client) Err(ctx context.Context, digest swarm.Digest, err error) error {
+	msg, has := cli.dequeue(digest)
+	if !has {
+		return nil
+	}
+
+	if err := cli.publish(ctx, msg.Topic(), msg.Payload()); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	msg.Ack()
+	return nil
+}
+
+func (cli *Client) dequeue(digest swarm.Digest) (paho.Message, bool) {
+	cli.Lock()
+	defer cli.Unlock()
+
+	msg, has := cli.inflight[digest]
+	delete(cli.inflight, digest)
+	return msg, has
+}
+
+// Ask returns batch of received messages, it waits until messages are
+// available or the wait time is expired.
+func (cli *Client) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	timer := time.NewTimer(cli.askWaitTime)
+	defer timer.Stop()
+
+	var msgs []paho.Message
+	select {
+	case msg := <-cli.ready:
+		msgs = append(msgs, msg)
+	case <-timer.C:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, nil
+	}
+
+	for len(msgs) < cli.batchSize {
+		select {
+		case msg := <-cli.ready:
+			msgs = append(msgs, msg)
+		default:
+			return cli.receive(msgs), nil
+		}
+	}
+
+	return cli.receive(msgs), nil
+}
+
+func (cli *Client) receive(msgs []paho.Message) []swarm.Bag {
+	cli.Lock()
+	defer cli.Unlock()
+
+	seq := make([]swarm.Bag, 0, len(msgs))
+	for _, msg := range msgs {
+		// Note: packet id is reused by the server, the digest is local sequence
+		cli.seq++
+		digest := swarm.Digest(strconv.FormatUint(cli.seq, 10))
+		cli.inflight[digest] = msg
+
+		seq = append(seq, swarm.Bag{
+			Category: cli.categoryOf(msg.Topic()),
+			Digest:   digest,
+			Object:   msg.Payload(),
+		})
+	}
+
+	return seq
+}
+
+// handler of messages delivered by the server, it blocks the network loop
+// when the buffer is full, so that memory is capped if the consumer is slow.
+func (cli *Client) recv(_ paho.Client, msg paho.Message) {
+	select {
+	case cli.ready <- msg:
+	case <-cli.closed:
+	}
+}
+
+func await(ctx context.Context, token paho.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// category of the topic is either defined by the route or
+// the topic itself relative to the root
+func (cli *Client) categoryOf(topic string) string {
+	for _, r := range cli.routes {
+		if match(filterOf(cli.root, r.Filter), topic) {
+			return r.Category
+		}
+	}
+
+	if cli.root == "" {
+		return topic
+	}
+	return strings.TrimPrefix(topic, cli.root+"/")
+}
+
+// MQTT reserves '+' and '#' wildcards in topic names, they are sanitized.
+// The category "a/b" is published to the topic "root/a/b".
+func topicOf(root, category string) string {
+	category = strings.Map(
+		func(r rune) rune {
+			switch r {
+			case '+', '#':
+				return '_'
+			default:
+				return r
+			}
+		},
+		category,
+	)
+
+	return filterOf(root, category)
+}
+
+func filterOf(root, filter string) string {
+	if root == "" {
+		return filter
+	}
+	return root + "/" + filter
+}
+
+// matches the topic against the filter, '+' matches single level,
+// '#' matches any number of levels.
+func match(filter, topic string) bool {
+	fs := strings.Split(filter, "/")
+	ts := strings.Split(topic, "/")
+
+	for i, f := range fs {
+		switch {
+		case f == "#":
+			return true
+		case i >= len(ts):
+			return false
+		case f != "+" && f != ts[i]:
+			return false
+		}
+	}
+
+	return len(fs) == len(ts)
+}
@@ -0,0 +1,210 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package mqtt_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/broker/mqtt"
+	"github.com/fogfish/swarm/emit"
+	"github.com/fogfish/swarm/listen"
+	server "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+func TestBroker(t *testing.T) {
+	url := runServer(t)
+
+	t.Run("Emit.Recv", func(t *testing.T) {
+		q, err := mqtt.Endpoint().WithURL(url).Build("test-emit-recv")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg := <-rcv
+			obj = msg.Object
+			ack <- msg
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Emit.Error.Recv", func(t *testing.T) {
+		q, err := mqtt.Endpoint().WithURL(url).Build("test-emit-err")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg1 := <-rcv
+			ack <- msg1.Fail(fmt.Errorf("fail"))
+
+			msg2 := <-rcv
+			obj = msg2.Object
+			ack <- msg2
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		ctx := context.Background()
+		r, err := mqtt.Listener().
+			WithURL(url).
+			WithCategory("Temperature", "sensor/+/temp").
+			WithCategory("Telemetry", "device/#").
+			WithWaitTime(100 * time.Millisecond).
+			Build("test-category")
+		it.Then(t).Should(it.Nil(err))
+		defer r.Close()
+
+		q, err := mqtt.Emitter().WithURL(url).Build("test-category")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		for _, cat := range []string{"sensor/1/temp", "sensor/1/humidity", "device/1"} {
+			err := q.Emitter.Enq(ctx, swarm.Bag{Category: cat, Object: []byte(cat)})
+			it.Then(t).Should(it.Nil(err))
+		}
+
+		a, err := r.Listener.Ask(ctx)
+		it.Then(t).Should(it.Nil(err))
+
+		b, err := r.Listener.Ask(ctx)
+		it.Then(t).Should(it.Nil(err))
+
+		c, err := r.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(c).BeEmpty(),
+			it.Equal(a[0].Category, "Temperature"),
+			it.Equal(string(a[0].Object), "sensor/1/temp"),
+			it.Equal(b[0].Category, "Telemetry"),
+			it.Equal(string(b[0].Object), "device/1"),
+		)
+	})
+
+	t.Run("Buffer", func(t *testing.T) {
+		ctx := context.Background()
+		r, err := mqtt.Listener().WithURL(url).WithBufferSize(1).WithBatchSize(5).WithWaitTime(time.Second).Build("test-buffer")
+		it.Then(t).Should(it.Nil(err))
+		defer r.Close()
+
+		q, err := mqtt.Emitter().WithURL(url).Build("test-buffer")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		for i := 0; i < 3; i++ {
+			err := q.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("test")})
+			it.Then(t).Should(it.Nil(err))
+		}
+
+		// delivery is blocked by full buffer, batch gets buffered message
+		// and the one released by the network loop
+		n := 0
+		for n < 3 {
+			seq, err := r.Listener.Ask(ctx)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.True(len(seq) >= 1 && len(seq) <= 2),
+			)
+			for _, bag := range seq {
+				it.Then(t).Should(it.Nil(r.Listener.Ack(ctx, bag.Digest)))
+			}
+			n += len(seq)
+		}
+	})
+
+	t.Run("Session.Redelivery", func(t *testing.T) {
+		ctx := context.Background()
+		listener := func() *mqtt.ListenerBuilder {
+			return mqtt.Listener().WithURL(url).WithClientID("test").WithWaitTime(time.Second)
+		}
+
+		a, err := listener().Build("test-session")
+		it.Then(t).Should(it.Nil(err))
+
+		q, err := mqtt.Emitter().WithURL(url).Build("test-session")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("unacked")})
+		it.Then(t).Should(it.Nil(err))
+
+		// the message is not acknowledged before disconnect
+		seq, err := a.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+		)
+		a.Close()
+
+		b, err := listener().Build("test-session")
+		it.Then(t).Should(it.Nil(err))
+		defer b.Close()
+
+		seq, err = b.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0].Category, "test"),
+			it.Equal(string(seq[0].Object), "unacked"),
+			it.Nil(b.Listener.Ack(ctx, seq[0].Digest)),
+		)
+	})
+}
+
+func runServer(t *testing.T) string {
+	t.Helper()
+
+	s := server.New(
+		&server.Options{
+			Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		},
+	)
+	it.Then(t).Should(
+		it.Nil(s.AddHook(new(auth.AllowHook), nil)),
+	)
+
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	it.Then(t).Should(
+		it.Nil(s.AddListener(tcp)),
+		it.Nil(s.Serve()),
+	)
+	t.Cleanup(func() { s.Close() })
+
+	return "tcp://" + tcp.Address()
+}
@@ -0,0 +1,272 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fogfish/logger/x/xlog"
+	"github.com/fogfish/opts"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Environment define name of source NATS JetStream stream
+const EnvConfigSourceNATS = "CONFIG_SWARM_SOURCE_NATS"
+
+// Environment define name of target NATS JetStream stream
+const EnvConfigTargetNATS = "CONFIG_SWARM_TARGET_NATS"
+
+func Must[T any](v T, err error) T {
+	if err != nil {
+		xlog.Emergency("nats broker has failed", err)
+	}
+	return v
+}
+
+type EndpointBuilder struct{ *builder[*EndpointBuilder] }
+
+func Endpoint() *EndpointBuilder {
+	b := &EndpointBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EndpointBuilder) Build(stream string) (*kernel.Kernel, error) {
+	client, err := b.build(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.applyConsumer(client); err != nil {
+		return nil, err
+	}
+
+	return kernel.New(
+		kernel.NewEmitter(client, client.config),
+		kernel.NewListener(client, client.config),
+	), nil
+}
+
+type EmitterBuilder struct{ *builder[*EmitterBuilder] }
+
+func Emitter() *EmitterBuilder {
+	b := &EmitterBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EmitterBuilder) Build(stream string) (*kernel.EmitterIO, error) {
+	client, err := b.build(stream)
+	if err != nil {
+		return nil, err
+	}
+	return kernel.NewEmitter(client, client.config), nil
+}
+
+type ListenerBuilder struct{ *builder[*ListenerBuilder] }
+
+func Listener() *ListenerBuilder {
+	b := &ListenerBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *ListenerBuilder) Build(stream string) (*kernel.ListenerIO, error) {
+	client, err := b.build(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.applyConsumer(client); err != nil {
+		return nil, err
+	}
+
+	return kernel.NewListener(client, client.config), nil
+}
+
+//------------------------------------------------------------------------------
+
+type builder[T any] struct {
+	b           T
+	kernelOpts  []opts.Option[swarm.Config]
+	conn        *nats.Conn
+	url         string
+	durable     string
+	ackWait     time.Duration
+	maxDeliver  int
+	batchSize   int
+	askWaitTime time.Duration
+}
+
+// newBuilder creates new builder for NATS JetStream broker configuration.
+func newBuilder[T any](b T) *builder[T] {
+	kopts := []opts.Option[swarm.Config]{
+		swarm.WithLogStdErr(),
+		swarm.WithConfigFromEnv(),
+	}
+
+	return &builder[T]{
+		b:           b,
+		kernelOpts:  kopts,
+		url:         nats.DefaultURL,
+		ackWait:     30 * time.Second,
+		batchSize:   1,
+		askWaitTime: 5 * time.Second,
+	}
+}
+
+// WithKernel configures swarm kernel options for advanced usage.
+func (b *builder[T]) WithKernel(opts ...opts.Option[swarm.Config]) T {
+	b.kernelOpts = append(b.kernelOpts, opts...)
+	return b.b
+}
+
+// WithService configures NATS connection instance, the connection is owned by application.
+func (b *builder[T]) WithService(conn *nats.Conn) T {
+	b.conn = conn
+	return b.b
+}
+
+// WithURL configures NATS server url, the broker owns the connection.
+func (b *builder[T]) WithURL(url string) T {
+	b.url = url
+	return b.b
+}
+
+// WithDurable configures name of durable pull consumer, the consumer
+// shares messages among all listeners with same name. Default is the agent name.
+func (b *builder[T]) WithDurable(name string) T {
+	b.durable = name
+	return b.b
+}
+
+// WithAckWait configures time of message redelivery if it is not acknowledged.
+func (b *builder[T]) WithAckWait(duration time.Duration) T {
+	b.ackWait = duration
+	return b.b
+}
+
+// WithMaxDeliver configures max number of deliveries for each message.
+func (b *builder[T]) WithMaxDeliver(n int) T {
+	b.maxDeliver = n
+	return b.b
+}
+
+// WithBatchSize configures number of messages fetched at once.
+func (b *builder[T]) WithBatchSize(size int) T {
+	b.batchSize = max(size, 1)
+	return b.b
+}
+
+// WithWaitTime configures max time to wait for messages.
+func (b *builder[T]) WithWaitTime(duration time.Duration) T {
+	b.askWaitTime = duration
+	return b.b
+}
+
+// build constructs the NATS client with configuration
+func (b *builder[T]) build(stream string) (*Client, error) {
+	client := &Client{
+		config:      swarm.NewConfig(),
+		conn:        b.conn,
+		stream:      stream,
+		batchSize:   b.batchSize,
+		askWaitTime: b.askWaitTime,
+		inflight:    make(map[uint64]jetstream.Msg),
+	}
+
+	if err := opts.Apply(&client.config, b.kernelOpts); err != nil {
+		return nil, err
+	}
+
+	if err := b.applyService(client); err != nil {
+		return nil, err
+	}
+
+	if err := b.applyStream(client); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (b *builder[T]) applyService(c *Client) error {
+	if c.conn == nil {
+		conn, err := nats.Connect(b.url, nats.Timeout(c.config.NetworkTimeout))
+		if err != nil {
+			return swarm.ErrServiceIO.With(err)
+		}
+		c.conn = conn
+		c.ownsConn = true
+	}
+
+	js, err := jetstream.New(c.conn)
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+	c.js = js
+
+	return nil
+}
+
+// the stream captures all categories, subject of category is "stream.category".
+// The existing stream is used as-is, its configuration is owned by operator.
+func (b *builder[T]) applyStream(c *Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.NetworkTimeout)
+	defer cancel()
+
+	stream, err := c.js.CreateStream(ctx,
+		jetstream.StreamConfig{
+			Name:        c.stream,
+			Subjects:    []string{c.stream + ".>"},
+			AllowMsgTTL: true,
+		},
+	)
+	if errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		stream, err = c.js.Stream(ctx, c.stream)
+	}
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	c.msgTTL = stream.CachedInfo().Config.AllowMsgTTL
+
+	return nil
+}
+
+func (b *builder[T]) applyConsumer(c *Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.NetworkTimeout)
+	defer cancel()
+
+	durable := b.durable
+	if durable == "" {
+		durable = token(c.config.Agent)
+	}
+
+	consumer, err := c.js.CreateOrUpdateConsumer(ctx, c.stream,
+		jetstream.ConsumerConfig{
+			Durable:       durable,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       b.ackWait,
+			MaxDeliver:    b.maxDeliver,
+			FilterSubject: c.stream + ".>",
+		},
+	)
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+	c.consumer = consumer
+
+	return nil
+}
@@ -0,0 +1,33 @@
+module github.com/fogfish/swarm/broker/nats
+
+go 1.24.0
+
+require (
+	github.com/fogfish/it/v2 v2.2.2
+	github.com/fogfish/logger/x/xlog v0.0.1
+	github.com/fogfish/opts v0.0.5
+	github.com/fogfish/swarm v0.24.1
+	github.com/nats-io/nats-server/v2 v2.12.4
+	github.com/nats-io/nats.go v1.49.0
+)
+
+require (
+	github.com/antithesishq/antithesis-sdk-go v0.8.0-default-no-op // indirect
+	github.com/fogfish/curie/v2 v2.1.2 // indirect
+	github.com/fogfish/faults v0.3.2 // indirect
+	github.com/fogfish/golem/hseq v1.3.0 // indirect
+	github.com/fogfish/golem/optics v0.14.0 // indirect
+	github.com/fogfish/guid/v2 v2.1.0 // indirect
+	github.com/fogfish/logger/v3 v3.2.0 // indirect
+	github.com/google/go-tpm v0.9.8 // indirect
+	github.com/klauspost/compress v1.19.2 // indirect
+	github.com/minio/highwayhash v1.0.4 // indirect
+	github.com/nats-io/jwt/v2 v2.8.0 // indirect
+	github.com/nats-io/nkeys v0.4.15 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.48.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/time v0.14.0 // indirect
+)
+
+replace github.com/fogfish/swarm => ../..
@@ -0,0 +1,184 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fogfish/swarm"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Message headers used by the broker
+const (
+	HeaderCategory = "Swarm-Category"
+	HeaderSource   = "Swarm-Source"
+)
+
+type Client struct {
+	config      swarm.Config
+	conn        *nats.Conn
+	ownsConn    bool
+	js          jetstream.JetStream
+	stream      string
+	consumer    jetstream.Consumer
+	batchSize   int
+	askWaitTime time.Duration
+
+	// stream supports per-message time-to-live
+	msgTTL bool
+
+	// in-flight messages, awaiting acknowledgement
+	sync.Mutex
+	inflight map[uint64]jetstream.Msg
+}
+
+func (cli *Client) Close() error {
+	if cli.ownsConn {
+		cli.conn.Close()
+	}
+	return nil
+}
+
+// Enq enqueues message to broker
+func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	msg := nats.NewMsg(subject(cli.stream, bag.Category))
+	msg.Header.Set(HeaderCategory, bag.Category)
+	msg.Header.Set(HeaderSource, cli.config.Agent)
+	msg.Data = bag.Object
+
+	// Note: the stream created by operator might not allow per-message TTL
+	if cli.msgTTL && !bag.Expires.IsZero() {
+		msg.Header.Set(jetstream.MsgTTLHeader, timeToLive(bag.Expires).String())
+	}
+
+	// max payload limits headers and data of the message
+	size := msg.Size() - len(msg.Subject)
+	if limit := int(cli.conn.MaxPayload()); limit > 0 && size > limit {
+		return swarm.ErrEnqueue.With(swarm.ErrTooLarge(size, limit))
+	}
+
+	if _, err := cli.js.PublishMsg(ctx, msg); err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	return nil
+}
+
+func (cli *Client) Ack(ctx context.Context, digest swarm.Digest) error {
+	msg, has := cli.dequeue(digest)
+	if !has {
+		return nil
+	}
+
+	if err := msg.Ack(); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return nil
+}
+
+func (cli *Client) Err(ctx context.Context, digest swarm.Digest, err error) error {
+	msg, has := cli.dequeue(digest)
+	if !has {
+		return nil
+	}
+
+	if err := msg.Nak(); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return nil
+}
+
+func (cli *Client) dequeue(digest swarm.Digest) (jetstream.Msg, bool) {
+	seq, err := strconv.ParseUint(string(digest), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	cli.Lock()
+	defer cli.Unlock()
+
+	msg, has := cli.inflight[seq]
+	delete(cli.inflight, seq)
+	return msg, has
+}
+
+// Ask dequeues messages from broker using durable pull consumer
+func (cli *Client) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	batch, err := cli.consumer.Fetch(cli.batchSize, jetstream.FetchMaxWait(cli.askWaitTime))
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, nats.ErrConnectionClosed) {
+			return nil, nil
+		}
+		return nil, swarm.ErrDequeue.With(err)
+	}
+
+	seq := make([]swarm.Bag, 0, cli.batchSize)
+	for msg := range batch.Messages() {
+		meta, err := msg.Metadata()
+		if err != nil {
+			continue
+		}
+
+		// Note: stream sequence is same for each delivery of the message,
+		// the redelivery replaces in-flight message.
+		cli.Lock()
+		cli.inflight[meta.Sequence.Stream] = msg
+		cli.Unlock()
+
+		seq = append(seq, swarm.Bag{
+			Category: msg.Headers().Get(HeaderCategory),
+			Digest:   swarm.Digest(strconv.FormatUint(meta.Sequence.Stream, 10)),
+			Object:   msg.Data(),
+		})
+	}
+
+	if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) && len(seq) == 0 {
+		return nil, swarm.ErrDequeue.With(err)
+	}
+
+	return seq, nil
+}
+
+// JetStream accepts time-to-live with seconds precision, at least one second
+func timeToLive(expires time.Time) time.Duration {
+	return max(time.Until(expires).Round(time.Second), time.Second)
+}
+
+// subject of the category within the stream
+func subject(stream, category string) string {
+	return stream + "." + token(category)
+}
+
+// NATS reserves '.', '*', '>', path separators and whitespaces in names,
+// the name is sanitized to the single token.
+func token(name string) string {
+	return strings.Map(
+		func(r rune) rune {
+			switch r {
+			case '.', '*', '>', '/', '\\', ' ', '\t', '\r', '\n':
+				return '_'
+			default:
+				return r
+			}
+		},
+		name,
+	)
+}
@@ -0,0 +1,217 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/broker/nats"
+	"github.com/fogfish/swarm/emit"
+	"github.com/fogfish/swarm/listen"
+	"github.com/nats-io/nats-server/v2/server"
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestBroker(t *testing.T) {
+	url := runServer(t)
+
+	t.Run("Emit.Recv", func(t *testing.T) {
+		q, err := nats.Endpoint().WithURL(url).Build("test-emit-recv")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg := <-rcv
+			obj = msg.Object
+			ack <- msg
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Emit.Error.Recv", func(t *testing.T) {
+		q, err := nats.Endpoint().WithURL(url).Build("test-emit-err")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg1 := <-rcv
+			ack <- msg1.Fail(fmt.Errorf("fail"))
+
+			msg2 := <-rcv
+			obj = msg2.Object
+			ack <- msg2
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		ctx := context.Background()
+		q, err := nats.Endpoint().WithURL(url).WithWaitTime(100 * time.Millisecond).Build("test-category")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Emitter.Enq(ctx, swarm.Bag{Category: "example.com/User", Object: []byte("user")})
+		it.Then(t).Should(it.Nil(err))
+
+		seq, err := q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0].Category, "example.com/User"),
+			it.Equal(string(seq[0].Object), "user"),
+			it.Nil(q.Listener.Listener.Ack(ctx, seq[0].Digest)),
+		)
+	})
+
+	t.Run("Enqueue.TooLarge", func(t *testing.T) {
+		q, err := nats.Emitter().WithURL(url).Build("test-too-large")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Enq(context.Background(), swarm.Bag{Category: "test", Object: make([]byte, 2*1024*1024)})
+
+		var e interface{ Limit() int }
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrEnqueue)),
+			it.True(errors.As(err, &e)),
+		)
+	})
+
+	t.Run("Stream.Existing", func(t *testing.T) {
+		ctx := context.Background()
+		js := jetStream(t, url)
+
+		_, err := js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     "test-existing",
+			Subjects: []string{"test-existing.>"},
+			MaxMsgs:  10,
+		})
+		it.Then(t).Should(it.Nil(err))
+
+		q, err := nats.Emitter().WithURL(url).Build("test-existing")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		stream, err := js.Stream(ctx, "test-existing")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(stream.CachedInfo().Config.MaxMsgs, 10),
+		)
+
+		// the stream does not allow per-message TTL
+		err = q.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("test"), Expires: time.Now().Add(time.Hour)})
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		ctx := context.Background()
+		q, err := nats.Emitter().WithURL(url).Build("test-expires")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("test"), Expires: time.Now().Add(time.Hour)})
+		it.Then(t).Should(it.Nil(err))
+
+		stream, err := jetStream(t, url).Stream(ctx, "test-expires")
+		it.Then(t).Should(it.Nil(err))
+
+		msg, err := stream.GetLastMsgForSubject(ctx, "test-expires.test")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(msg.Header.Get(jetstream.MsgTTLHeader), "1h0m0s"),
+		)
+	})
+
+	t.Run("Redelivery", func(t *testing.T) {
+		ctx := context.Background()
+		q, err := nats.Endpoint().WithURL(url).WithAckWait(100 * time.Millisecond).WithWaitTime(time.Second).Build("test-redelivery")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("test")})
+		it.Then(t).Should(it.Nil(err))
+
+		a, err := q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(it.Nil(err), it.Equal(len(a), 1))
+
+		b, err := q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(b), 1),
+			it.Equal(a[0].Digest, b[0].Digest),
+			it.Nil(q.Listener.Listener.Ack(ctx, b[0].Digest)),
+		)
+	})
+}
+
+func jetStream(t *testing.T, url string) jetstream.JetStream {
+	t.Helper()
+
+	conn, err := natsio.Connect(url)
+	it.Then(t).Should(it.Nil(err))
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	it.Then(t).Should(it.Nil(err))
+
+	return js
+}
+
+func runServer(t *testing.T) string {
+	t.Helper()
+
+	s, err := server.NewServer(
+		&server.Options{
+			Host:      "127.0.0.1",
+			Port:      -1,
+			JetStream: true,
+			StoreDir:  t.TempDir(),
+			NoLog:     true,
+			NoSigs:    true,
+		},
+	)
+	it.Then(t).Should(it.Nil(err))
+
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server is not ready")
+	}
+	t.Cleanup(s.Shutdown)
+
+	return s.ClientURL()
+}
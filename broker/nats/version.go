@@ -0,0 +1,15 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package nats
+
+// MAJOR.MINOR.PATCH
+// MAJOR - incompatible api changes
+// MINOR - version of the event kernel
+// PATCH - version of the event bridge module
+const Version = "broker/nats/v0.24.1"
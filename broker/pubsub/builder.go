@@ -0,0 +1,279 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package pubsub
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"github.com/fogfish/logger/x/xlog"
+	"github.com/fogfish/opts"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Environment define name of source Pub/Sub topic
+const EnvConfigSourcePubSub = "CONFIG_SWARM_SOURCE_PUBSUB"
+
+// Environment define name of target Pub/Sub topic
+const EnvConfigTargetPubSub = "CONFIG_SWARM_TARGET_PUBSUB"
+
+// Environment define Google Cloud project
+const EnvGoogleCloudProject = "GOOGLE_CLOUD_PROJECT"
+
+func Must[T any](v T, err error) T {
+	if err != nil {
+		xlog.Emergency("pubsub broker has failed", err)
+	}
+	return v
+}
+
+type EndpointBuilder struct{ *builder[*EndpointBuilder] }
+
+func Endpoint() *EndpointBuilder {
+	b := &EndpointBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EndpointBuilder) Build(topic string) (*kernel.Kernel, error) {
+	client, err := b.build(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.applySubscription(client); err != nil {
+		return nil, err
+	}
+
+	return kernel.New(
+		kernel.NewEmitter(client, client.config),
+		kernel.NewListener(client, client.config),
+	), nil
+}
+
+type EmitterBuilder struct{ *builder[*EmitterBuilder] }
+
+func Emitter() *EmitterBuilder {
+	b := &EmitterBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *EmitterBuilder) Build(topic string) (*kernel.EmitterIO, error) {
+	client, err := b.build(topic)
+	if err != nil {
+		return nil, err
+	}
+	return kernel.NewEmitter(client, client.config), nil
+}
+
+type ListenerBuilder struct{ *builder[*ListenerBuilder] }
+
+func Listener() *ListenerBuilder {
+	b := &ListenerBuilder{}
+	b.builder = newBuilder(b)
+	return b
+}
+
+func (b *ListenerBuilder) Build(topic string) (*kernel.ListenerIO, error) {
+	client, err := b.build(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.applySubscription(client); err != nil {
+		return nil, err
+	}
+
+	return kernel.NewListener(client, client.config), nil
+}
+
+//------------------------------------------------------------------------------
+
+// range of ack deadline supported by Pub/Sub
+const (
+	minAckDeadline = 10 * time.Second
+	maxAckDeadline = 600 * time.Second
+)
+
+type builder[T any] struct {
+	b            T
+	kernelOpts   []opts.Option[swarm.Config]
+	clientOpts   []option.ClientOption
+	service      *pubsub.Client
+	project      string
+	subscription string
+	ackDeadline  time.Duration
+	maxExtension time.Duration
+	batchSize    int
+	askWaitTime  time.Duration
+}
+
+// newBuilder creates new builder for Google Cloud Pub/Sub broker configuration.
+func newBuilder[T any](b T) *builder[T] {
+	kopts := []opts.Option[swarm.Config]{
+		swarm.WithLogStdErr(),
+		swarm.WithConfigFromEnv(),
+	}
+
+	return &builder[T]{
+		b:            b,
+		kernelOpts:   kopts,
+		project:      os.Getenv(EnvGoogleCloudProject),
+		ackDeadline:  30 * time.Second,
+		maxExtension: 10 * time.Minute,
+		batchSize:    1,
+		askWaitTime:  5 * time.Second,
+	}
+}
+
+// WithKernel configures swarm kernel options for advanced usage.
+func (b *builder[T]) WithKernel(opts ...opts.Option[swarm.Config]) T {
+	b.kernelOpts = append(b.kernelOpts, opts...)
+	return b.b
+}
+
+// WithService configures Pub/Sub client instance, the client is owned by application.
+func (b *builder[T]) WithService(service *pubsub.Client) T {
+	b.service = service
+	return b.b
+}
+
+// WithClientOptions configures Pub/Sub client options (e.g. credentials, endpoint).
+func (b *builder[T]) WithClientOptions(opts ...option.ClientOption) T {
+	b.clientOpts = append(b.clientOpts, opts...)
+	return b.b
+}
+
+// WithProject configures Google Cloud project, default is defined by
+// GOOGLE_CLOUD_PROJECT environment variable.
+func (b *builder[T]) WithProject(project string) T {
+	b.project = project
+	return b.b
+}
+
+// WithSubscription configures name of subscription, the subscription shares
+// messages among all listeners with same name. It is created if not exists.
+// Default is "topic-agent".
+func (b *builder[T]) WithSubscription(name string) T {
+	b.subscription = name
+	return b.b
+}
+
+// WithAckDeadline configures ack deadline of messages. The deadline of
+// in-flight messages is extended until they are acknowledged. Pub/Sub accepts
+// deadline from 10 to 600 seconds, the value is clamped to the range.
+func (b *builder[T]) WithAckDeadline(deadline time.Duration) T {
+	b.ackDeadline = min(max(deadline, minAckDeadline), maxAckDeadline)
+	return b.b
+}
+
+// WithMaxExtension configures max time of ack deadline extension, the message
+// is redelivered afterwards. Zero value extends the deadline until acknowledged.
+func (b *builder[T]) WithMaxExtension(duration time.Duration) T {
+	b.maxExtension = duration
+	return b.b
+}
+
+// WithBatchSize configures number of messages pulled at once.
+func (b *builder[T]) WithBatchSize(size int) T {
+	b.batchSize = max(size, 1)
+	return b.b
+}
+
+// WithWaitTime configures max time to wait for messages.
+func (b *builder[T]) WithWaitTime(duration time.Duration) T {
+	b.askWaitTime = duration
+	return b.b
+}
+
+// build constructs the Pub/Sub client with configuration
+func (b *builder[T]) build(topic string) (*Client, error) {
+	client := &Client{
+		config:       swarm.NewConfig(),
+		service:      b.service,
+		batchSize:    b.batchSize,
+		askWaitTime:  b.askWaitTime,
+		ackDeadline:  b.ackDeadline,
+		maxExtension: b.maxExtension,
+		inflight:     make(map[swarm.Digest]time.Time),
+	}
+
+	if err := opts.Apply(&client.config, b.kernelOpts); err != nil {
+		return nil, err
+	}
+
+	if err := b.applyService(client); err != nil {
+		return nil, err
+	}
+
+	client.topic = resource(client.service.Project(), "topics", topic)
+
+	return client, nil
+}
+
+func (b *builder[T]) applyService(c *Client) error {
+	if c.service != nil {
+		return nil
+	}
+
+	service, err := pubsub.NewClient(context.Background(), b.project, b.clientOpts...)
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	c.service = service
+	c.ownsService = true
+
+	return nil
+}
+
+// the subscription is created for the topic if not exists,
+// the listener extends ack deadline of in-flight messages.
+func (b *builder[T]) applySubscription(c *Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.NetworkTimeout)
+	defer cancel()
+
+	subscription := b.subscription
+	if subscription == "" {
+		subscription = path.Base(c.topic) + "-" + strings.ReplaceAll(c.config.Agent, "/", "_")
+	}
+	c.subscription = resource(c.service.Project(), "subscriptions", subscription)
+
+	_, err := c.service.SubscriptionAdminClient.GetSubscription(ctx,
+		&pubsubpb.GetSubscriptionRequest{Subscription: c.subscription},
+	)
+	if status.Code(err) == codes.NotFound {
+		_, err = c.service.SubscriptionAdminClient.CreateSubscription(ctx,
+			&pubsubpb.Subscription{
+				Name:               c.subscription,
+				Topic:              c.topic,
+				AckDeadlineSeconds: int32(b.ackDeadline / time.Second),
+			},
+		)
+	}
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	lease, cancelLease := context.WithCancel(context.Background())
+	c.cancel = cancelLease
+	go c.lease(lease)
+
+	return nil
+}
@@ -0,0 +1,246 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"github.com/fogfish/swarm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Google Cloud Pub/Sub limits size of message
+const MaxMessageSize = 10 * 1024 * 1024
+
+// Message attribute used by the broker
+const AttributeCategory = "Category"
+
+type Client struct {
+	config       swarm.Config
+	service      *pubsub.Client
+	ownsService  bool
+	topic        string
+	subscription string
+	batchSize    int
+	askWaitTime  time.Duration
+	ackDeadline  time.Duration
+	maxExtension time.Duration
+
+	// in-flight messages (ack id and time of receive), awaiting acknowledgement
+	sync.Mutex
+	inflight map[swarm.Digest]time.Time
+	cancel   context.CancelFunc
+}
+
+func (cli *Client) Close() error {
+	if cli.cancel != nil {
+		cli.cancel()
+	}
+
+	if cli.ownsService {
+		return cli.service.Close()
+	}
+	return nil
+}
+
+// Enq enqueues message to broker
+func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	if len(bag.Object) > MaxMessageSize {
+		return swarm.ErrEnqueue.With(swarm.ErrTooLarge(len(bag.Object), MaxMessageSize))
+	}
+
+	_, err := cli.service.TopicAdminClient.Publish(ctx,
+		&pubsubpb.PublishRequest{
+			Topic: cli.topic,
+			Messages: []*pubsubpb.PubsubMessage{
+				{
+					Data:       bag.Object,
+					Attributes: map[string]string{AttributeCategory: bag.Category},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	return nil
+}
+
+func (cli *Client) Ack(ctx context.Context, digest swarm.Digest) error {
+	if !cli.dequeue(digest) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	err := cli.service.SubscriptionAdminClient.Acknowledge(ctx,
+		&pubsubpb.AcknowledgeRequest{
+			Subscription: cli.subscription,
+			AckIds:       []string{string(digest)},
+		},
+	)
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return nil
+}
+
+// Err nacks the message, the zero ack deadline makes it available for redelivery.
+func (cli *Client) Err(ctx context.Context, digest swarm.Digest, err error) error {
+	if !cli.dequeue(digest) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	if err := cli.modifyAckDeadline(ctx, []string{string(digest)}, 0); err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return nil
+}
+
+func (cli *Client) dequeue(digest swarm.Digest) bool {
+	cli.Lock()
+	defer cli.Unlock()
+
+	_, has := cli.inflight[digest]
+	delete(cli.inflight, digest)
+	return has
+}
+
+// Ask dequeues messages from broker using synchronous pull of subscription
+func (cli *Client) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	ctx, cancel := context.WithTimeout(ctx, cli.askWaitTime)
+	defer cancel()
+
+	result, err := cli.service.SubscriptionAdminClient.Pull(ctx,
+		&pubsubpb.PullRequest{
+			Subscription: cli.subscription,
+			MaxMessages:  int32(cli.batchSize),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return nil, nil
+		}
+
+		switch status.Code(err) {
+		case codes.DeadlineExceeded, codes.Canceled:
+			return nil, nil
+		}
+
+		return nil, swarm.ErrDequeue.With(err)
+	}
+
+	now := time.Now()
+	seq := make([]swarm.Bag, len(result.ReceivedMessages))
+
+	cli.Lock()
+	for i, msg := range result.ReceivedMessages {
+		// Note: ack id is unique for each delivery of the message
+		digest := swarm.Digest(msg.AckId)
+		cli.inflight[digest] = now
+
+		seq[i] = swarm.Bag{
+			Category: msg.Message.Attributes[AttributeCategory],
+			Digest:   digest,
+			Object:   msg.Message.Data,
+		}
+	}
+	cli.Unlock()
+
+	return seq, nil
+}
+
+// lease extends ack deadline of in-flight messages until they are acknowledged
+// or the max extension is expired, the message is redelivered afterwards.
+// The message stays in-flight after expiry, its ack or nack still reaches the service.
+func (cli *Client) lease(ctx context.Context) {
+	ticker := time.NewTicker(cli.ackDeadline / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cli.extend(ctx); err != nil && ctx.Err() == nil {
+				slog.Warn("pubsub ack deadline extension has failed",
+					slog.Any("subscription", cli.subscription),
+					slog.Any("err", err),
+				)
+			}
+		}
+	}
+}
+
+func (cli *Client) extend(ctx context.Context) error {
+	ids := cli.leased()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	// Note: deadline is defined in seconds, zero deadline nacks messages
+	return cli.modifyAckDeadline(ctx, ids, int32(math.Ceil(cli.ackDeadline.Seconds())))
+}
+
+// ack ids of messages eligible for extension
+func (cli *Client) leased() []string {
+	cli.Lock()
+	defer cli.Unlock()
+
+	now := time.Now()
+	ids := make([]string, 0, len(cli.inflight))
+	for digest, at := range cli.inflight {
+		if cli.maxExtension != 0 && now.Sub(at) > cli.maxExtension {
+			continue
+		}
+		ids = append(ids, string(digest))
+	}
+
+	return ids
+}
+
+func (cli *Client) modifyAckDeadline(ctx context.Context, ids []string, seconds int32) error {
+	return cli.service.SubscriptionAdminClient.ModifyAckDeadline(ctx,
+		&pubsubpb.ModifyAckDeadlineRequest{
+			Subscription:       cli.subscription,
+			AckIds:             ids,
+			AckDeadlineSeconds: seconds,
+		},
+	)
+}
+
+// resource name of topic or subscription, the full name is used as-is
+func resource(project, kind, id string) string {
+	if strings.HasPrefix(id, "projects/") {
+		return id
+	}
+	return "projects/" + project + "/" + kind + "/" + id
+}
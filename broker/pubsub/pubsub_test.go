@@ -0,0 +1,224 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	gcp "cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/v2/pstest"
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/broker/pubsub"
+	"github.com/fogfish/swarm/emit"
+	"github.com/fogfish/swarm/listen"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestBroker(t *testing.T) {
+	srv, service := runServer(t, "test-emit-recv", "test-emit-err", "test-category", "test-lease", "test-expire")
+
+	t.Run("Emit.Recv", func(t *testing.T) {
+		q, err := pubsub.Endpoint().WithService(service).Build("test-emit-recv")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg := <-rcv
+			obj = msg.Object
+			ack <- msg
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Emit.Error.Recv", func(t *testing.T) {
+		q, err := pubsub.Endpoint().WithService(service).Build("test-emit-err")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg1 := <-rcv
+			ack <- msg1.Fail(fmt.Errorf("fail"))
+
+			msg2 := <-rcv
+			obj = msg2.Object
+			ack <- msg2
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		ctx := context.Background()
+		q, err := pubsub.Endpoint().WithService(service).Build("test-category")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Emitter.Enq(ctx, swarm.Bag{Category: "example.com/User", Object: []byte("user")})
+		it.Then(t).Should(it.Nil(err))
+
+		seq, err := q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0].Category, "example.com/User"),
+			it.Equal(string(seq[0].Object), "user"),
+			it.Nil(q.Listener.Listener.Ack(ctx, seq[0].Digest)),
+		)
+	})
+
+	t.Run("AckDeadline.Extension", func(t *testing.T) {
+		ctx := context.Background()
+
+		// Note: the deadline is clamped to 10 seconds, it is extended every 5 seconds
+		q, err := pubsub.Endpoint().
+			WithService(service).
+			WithSubscription("test-lease").
+			WithAckDeadline(0).
+			Build("test-lease")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("lease")})
+		it.Then(t).Should(it.Nil(err))
+
+		seq, err := q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+		)
+
+		time.Sleep(5500 * time.Millisecond)
+
+		var modacks []pstest.Modack
+		for _, msg := range srv.Messages() {
+			if string(msg.Data) == "lease" {
+				modacks = msg.Modacks
+			}
+		}
+
+		sub, err := service.SubscriptionAdminClient.GetSubscription(ctx,
+			&pubsubpb.GetSubscriptionRequest{Subscription: "projects/test/subscriptions/test-lease"},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(sub.AckDeadlineSeconds, 10),
+			it.Nil(q.Listener.Listener.Ack(ctx, seq[0].Digest)),
+			it.Less(0, len(modacks)),
+		)
+
+		for _, modack := range modacks {
+			it.Then(t).Should(
+				it.Equal(modack.AckID, string(seq[0].Digest)),
+				it.Equal(modack.AckDeadline, 10),
+			)
+		}
+	})
+
+	t.Run("AckDeadline.MaxExtension", func(t *testing.T) {
+		ctx := context.Background()
+
+		q, err := pubsub.Endpoint().
+			WithService(service).
+			WithSubscription("test-expire").
+			WithAckDeadline(0).
+			WithMaxExtension(time.Millisecond).
+			Build("test-expire")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("expire")})
+		it.Then(t).Should(it.Nil(err))
+
+		seq, err := q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+		)
+
+		time.Sleep(5500 * time.Millisecond)
+		it.Then(t).Should(
+			it.Nil(q.Listener.Listener.Ack(ctx, seq[0].Digest)),
+		)
+
+		for _, msg := range srv.Messages() {
+			if string(msg.Data) == "expire" {
+				it.Then(t).Should(
+					it.Equal(len(msg.Modacks), 0),
+					it.Equal(msg.Acks, 1),
+				)
+			}
+		}
+	})
+
+	t.Run("Enqueue.TooLarge", func(t *testing.T) {
+		q, err := pubsub.Emitter().WithService(service).Build("test-too-large")
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(), swarm.Bag{Category: "test", Object: make([]byte, pubsub.MaxMessageSize+1)})
+
+		var e interface{ Limit() int }
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrEnqueue)),
+			it.True(errors.As(err, &e)),
+		)
+	})
+}
+
+func runServer(t *testing.T, topics ...string) (*pstest.Server, *gcp.Client) {
+	t.Helper()
+
+	srv := pstest.NewServer()
+	t.Cleanup(func() { srv.Close() })
+
+	service, err := gcp.NewClient(context.Background(), "test",
+		option.WithEndpoint(srv.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	it.Then(t).Should(it.Nil(err))
+	t.Cleanup(func() { service.Close() })
+
+	for _, topic := range topics {
+		_, err := service.TopicAdminClient.CreateTopic(context.Background(),
+			&pubsubpb.Topic{Name: "projects/test/topics/" + topic},
+		)
+		it.Then(t).Should(it.Nil(err))
+	}
+
+	return srv, service
+}
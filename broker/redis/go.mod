@@ -0,0 +1,28 @@
+module github.com/fogfish/swarm/broker/redis
+
+go 1.24
+
+require (
+	github.com/alicebob/miniredis/v2 v2.39.0
+	github.com/fogfish/guid/v2 v2.1.0
+	github.com/fogfish/it/v2 v2.2.2
+	github.com/fogfish/logger/x/xlog v0.0.1
+	github.com/fogfish/opts v0.0.5
+	github.com/fogfish/swarm v0.24.1
+	github.com/redis/go-redis/v9 v9.22.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/fogfish/curie/v2 v2.1.2 // indirect
+	github.com/fogfish/faults v0.3.2 // indirect
+	github.com/fogfish/golem/hseq v1.3.0 // indirect
+	github.com/fogfish/golem/optics v0.14.0 // indirect
+	github.com/fogfish/logger/v3 v3.2.0 // indirect
+	github.com/stretchr/testify v1.11.1 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
+
+replace github.com/fogfish/swarm => ../..
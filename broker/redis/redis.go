@@ -0,0 +1,215 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fogfish/swarm"
+	"github.com/redis/go-redis/v9"
+)
+
+// Fields of stream entry used by the broker
+const (
+	FieldCategory = "category"
+	FieldSource   = "source"
+	FieldObject   = "object"
+)
+
+type Client struct {
+	config      swarm.Config
+	service     redis.UniversalClient
+	ownsService bool
+	stream      string
+	maxLen      int64
+	group       string
+	consumer    string
+	batchSize   int
+	askWaitTime time.Duration
+
+	// pending entries idle longer than claimIdle are claimed by the consumer,
+	// the cursor is shared by pollers.
+	sync.Mutex
+	claimIdle   time.Duration
+	claimCursor string
+
+	// generated consumer is removed from the group on close
+	ownsConsumer bool
+}
+
+func (cli *Client) Close() error {
+	cli.removeConsumer()
+
+	if cli.ownsService {
+		return cli.service.Close()
+	}
+	return nil
+}
+
+// Note: the consumer is kept while it has pending messages, otherwise
+// they are not re-delivered. Other consumers claim them after idle time.
+func (cli *Client) removeConsumer() {
+	cli.Lock()
+	owns := cli.ownsConsumer
+	cli.ownsConsumer = false
+	cli.Unlock()
+
+	if !owns {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cli.config.NetworkTimeout)
+	defer cancel()
+
+	pending, err := cli.service.XPendingExt(ctx,
+		&redis.XPendingExtArgs{
+			Stream:   cli.stream,
+			Group:    cli.group,
+			Consumer: cli.consumer,
+			Start:    "-",
+			End:      "+",
+			Count:    1,
+		},
+	).Result()
+	if err != nil || len(pending) != 0 {
+		return
+	}
+
+	cli.service.XGroupDelConsumer(ctx, cli.stream, cli.group, cli.consumer)
+}
+
+// Enq enqueues message to broker
+func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	err := cli.service.XAdd(ctx,
+		&redis.XAddArgs{
+			Stream: cli.stream,
+			MaxLen: cli.maxLen,
+			Approx: cli.maxLen > 0,
+			Values: []any{
+				FieldCategory, bag.Category,
+				FieldSource, cli.config.Agent,
+				FieldObject, bag.Object,
+			},
+		},
+	).Err()
+	if err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	return nil
+}
+
+func (cli *Client) Ack(ctx context.Context, digest swarm.Digest) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	err := cli.service.XAck(ctx, cli.stream, cli.group, string(digest)).Err()
+	if err != nil {
+		return swarm.ErrServiceIO.With(err)
+	}
+
+	return nil
+}
+
+// Err keeps the message in the pending list of consumer group, the message is
+// re-delivered once it is claimed after the idle time (see WithClaimIdle).
+// Note: the message is re-delivered infinitely, the stream has no dead-letter.
+func (cli *Client) Err(ctx context.Context, digest swarm.Digest, err error) error {
+	return nil
+}
+
+// Ask dequeues messages from broker, stuck messages of the consumer group are
+// claimed first, new messages are read otherwise.
+func (cli *Client) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	seq, err := cli.claim(ctx)
+	if err != nil || len(seq) != 0 {
+		return seq, err
+	}
+
+	return cli.read(ctx)
+}
+
+func (cli *Client) claim(ctx context.Context) ([]swarm.Bag, error) {
+	cli.Lock()
+	start := cli.claimCursor
+	cli.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	msgs, cursor, err := cli.service.XAutoClaim(ctx,
+		&redis.XAutoClaimArgs{
+			Stream:   cli.stream,
+			Group:    cli.group,
+			Consumer: cli.consumer,
+			MinIdle:  cli.claimIdle,
+			Start:    start,
+			Count:    int64(cli.batchSize),
+		},
+	).Result()
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, nil
+		}
+		return nil, swarm.ErrDequeue.With(err)
+	}
+
+	cli.Lock()
+	cli.claimCursor = cursor
+	cli.Unlock()
+
+	return bags(msgs), nil
+}
+
+func (cli *Client) read(ctx context.Context) ([]swarm.Bag, error) {
+	streams, err := cli.service.XReadGroup(ctx,
+		&redis.XReadGroupArgs{
+			Group:    cli.group,
+			Consumer: cli.consumer,
+			Streams:  []string{cli.stream, ">"},
+			Count:    int64(cli.batchSize),
+			Block:    cli.askWaitTime,
+		},
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, redis.ErrClosed) {
+			return nil, nil
+		}
+		return nil, swarm.ErrDequeue.With(err)
+	}
+
+	seq := make([]swarm.Bag, 0, cli.batchSize)
+	for _, stream := range streams {
+		seq = append(seq, bags(stream.Messages)...)
+	}
+
+	return seq, nil
+}
+
+// Note: id of the stream entry is the digest of message
+func bags(msgs []redis.XMessage) []swarm.Bag {
+	seq := make([]swarm.Bag, 0, len(msgs))
+	for _, msg := range msgs {
+		category, _ := msg.Values[FieldCategory].(string)
+		object, _ := msg.Values[FieldObject].(string)
+
+		seq = append(seq, swarm.Bag{
+			Category: category,
+			Digest:   swarm.Digest(msg.ID),
+			Object:   []byte(object),
+		})
+	}
+	return seq
+}
@@ -0,0 +1,197 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/broker/redis"
+	"github.com/fogfish/swarm/emit"
+	"github.com/fogfish/swarm/listen"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestBroker(t *testing.T) {
+	srv := miniredis.RunT(t)
+	url := "redis://" + srv.Addr()
+
+	t.Run("Emit.Recv", func(t *testing.T) {
+		q, err := redis.Endpoint().WithURL(url).WithWaitTime(100 * time.Millisecond).Build("test-emit-recv")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg := <-rcv
+			obj = msg.Object
+			ack <- msg
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Emit.Error.Recv", func(t *testing.T) {
+		q, err := redis.Endpoint().
+			WithURL(url).
+			WithWaitTime(10 * time.Millisecond).
+			WithClaimIdle(10 * time.Millisecond).
+			Build("test-emit-err")
+		it.Then(t).Should(it.Nil(err))
+
+		var obj string
+		snd := swarm.LogDeadLetters(emit.Typed[string](q.Emitter))
+		rcv, ack := listen.Typed[string](q.Listener)
+
+		snd <- "hello world"
+		go func() {
+			msg1 := <-rcv
+			ack <- msg1.Fail(fmt.Errorf("fail"))
+
+			msg2 := <-rcv
+			obj = msg2.Object
+			ack <- msg2
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+		q.Await()
+
+		it.Then(t).Should(
+			it.Equal(obj, "hello world"),
+		)
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		ctx := context.Background()
+		q, err := redis.Endpoint().WithURL(url).WithWaitTime(100 * time.Millisecond).Build("test-category")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Emitter.Enq(ctx, swarm.Bag{Category: "example.com/User", Object: []byte("user")})
+		it.Then(t).Should(it.Nil(err))
+
+		seq, err := q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0].Category, "example.com/User"),
+			it.Equal(string(seq[0].Object), "user"),
+			it.Nil(q.Listener.Listener.Ack(ctx, seq[0].Digest)),
+		)
+
+		seq, err = q.Listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).BeEmpty(),
+		)
+	})
+
+	t.Run("Group.Claim", func(t *testing.T) {
+		ctx := context.Background()
+		builder := func(consumer string) *redis.ListenerBuilder {
+			return redis.Listener().
+				WithURL(url).
+				WithGroup("test").
+				WithConsumer(consumer).
+				WithWaitTime(10 * time.Millisecond).
+				WithClaimIdle(20 * time.Millisecond)
+		}
+
+		a, err := builder("a").Build("test-claim")
+		it.Then(t).Should(it.Nil(err))
+		defer a.Close()
+
+		b, err := builder("b").Build("test-claim")
+		it.Then(t).Should(it.Nil(err))
+		defer b.Close()
+
+		q, err := redis.Emitter().WithURL(url).Build("test-claim")
+		it.Then(t).Should(it.Nil(err))
+		defer q.Close()
+
+		err = q.Emitter.Enq(ctx, swarm.Bag{Category: "test", Object: []byte("stuck")})
+		it.Then(t).Should(it.Nil(err))
+
+		// consumer "a" gets stuck with the message
+		seq, err := a.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+		)
+
+		seq, err = b.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).BeEmpty(),
+		)
+
+		time.Sleep(30 * time.Millisecond)
+		seq, err = b.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Equal(string(seq[0].Object), "stuck"),
+			it.Nil(b.Listener.Ack(ctx, seq[0].Digest)),
+		)
+
+		time.Sleep(30 * time.Millisecond)
+		seq, err = a.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).BeEmpty(),
+		)
+	})
+	t.Run("Group.Consumer", func(t *testing.T) {
+		ctx := context.Background()
+		cli := goredis.NewClient(&goredis.Options{Addr: srv.Addr()})
+		defer cli.Close()
+
+		q, err := redis.Listener().WithURL(url).WithGroup("test").WithWaitTime(10 * time.Millisecond).Build("test-consumer")
+		it.Then(t).Should(it.Nil(err))
+
+		err = cli.XAdd(ctx, &goredis.XAddArgs{Stream: "test-consumer", Values: []any{redis.FieldCategory, "test"}}).Err()
+		it.Then(t).Should(it.Nil(err))
+
+		seq, err := q.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Nil(q.Listener.Ack(ctx, seq[0].Digest)),
+		)
+
+		consumers, err := cli.XInfoConsumers(ctx, "test-consumer", "test").Result()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(consumers), 1),
+		)
+
+		q.Close()
+
+		consumers, err = cli.XInfoConsumers(ctx, "test-consumer", "test").Result()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(consumers).BeEmpty(),
+		)
+	})
+}
@@ -0,0 +1,210 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package sns
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambdaeventsources"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssns"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssnssubscriptions"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+
+	"github.com/fogfish/scud"
+	"github.com/fogfish/swarm"
+)
+
+//------------------------------------------------------------------------------
+//
+// AWS CDK Sink Construct
+//
+//------------------------------------------------------------------------------
+
+// Sink is SQS queue subscribed to SNS topic and Lambda function consuming the queue.
+type Sink struct {
+	constructs.Construct
+	Queue   awssqs.IQueue
+	Handler awslambda.Function
+}
+
+type SinkProps struct {
+	Agent *string
+	Topic awssns.ITopic
+	// Categories of messages delivered to the sink, all messages are delivered if empty.
+	Categories []string
+	Function   scud.FunctionProps
+}
+
+func (props *SinkProps) assert() {
+	if props.Function == nil {
+		panic("Function is not defined.")
+	}
+	if props.Topic == nil {
+		panic("Topic is not defined.")
+	}
+}
+
+func NewSink(scope constructs.Construct, id *string, props *SinkProps) *Sink {
+	props.assert()
+
+	sink := &Sink{Construct: constructs.NewConstruct(scope, id)}
+
+	// Note: FIFO topic delivers messages only to FIFO queue
+	sink.Queue = awssqs.NewQueue(sink.Construct, jsii.String("Queue"),
+		&awssqs.QueueProps{
+			Fifo:              props.Topic.Fifo(),
+			VisibilityTimeout: awscdk.Duration_Minutes(jsii.Number(15.0)),
+		},
+	)
+
+	var filter *map[string]awssns.SubscriptionFilter
+	if len(props.Categories) > 0 {
+		filter = &map[string]awssns.SubscriptionFilter{
+			"Category": awssns.SubscriptionFilter_StringFilter(
+				&awssns.StringConditions{
+					Allowlist: jsii.Strings(props.Categories...),
+				},
+			),
+		}
+	}
+
+	props.Topic.AddSubscription(
+		awssnssubscriptions.NewSqsSubscription(sink.Queue,
+			&awssnssubscriptions.SqsSubscriptionProps{
+				RawMessageDelivery: jsii.Bool(true),
+				FilterPolicy:       filter,
+			},
+		),
+	)
+
+	sink.Handler = scud.NewFunction(sink.Construct, jsii.String("Func"), props.Function)
+	sink.Handler.AddEnvironment(
+		jsii.String(EnvConfigSourceSNS),
+		props.Topic.TopicArn(),
+		nil,
+	)
+
+	if props.Agent != nil {
+		sink.Handler.AddEnvironment(
+			jsii.String(swarm.EnvConfigAgent),
+			props.Agent,
+			nil,
+		)
+	}
+
+	if ttf := timeToFlight(props.Function); ttf != nil {
+		tsf := ttf.ToSeconds(nil)
+		tsi := int(aws.ToFloat64(tsf))
+
+		sink.Handler.AddEnvironment(
+			jsii.String(swarm.EnvConfigTimeToFlight),
+			jsii.String(strconv.Itoa(tsi)),
+			nil,
+		)
+	}
+
+	source := awslambdaeventsources.NewSqsEventSource(sink.Queue,
+		&awslambdaeventsources.SqsEventSourceProps{})
+
+	sink.Handler.AddEventSource(source)
+
+	return sink
+}
+
+func timeToFlight(props scud.FunctionProps) awscdk.Duration {
+	if props == nil {
+		return nil
+	}
+
+	switch v := props.(type) {
+	case *scud.FunctionGoProps:
+		if v.FunctionProps != nil && v.Timeout != nil {
+			return v.Timeout
+		}
+	case *scud.ContainerGoProps:
+		if v.DockerImageFunctionProps != nil && v.Timeout != nil {
+			return v.Timeout
+		}
+	}
+
+	return nil
+}
+
+//------------------------------------------------------------------------------
+//
+// AWS CDK Stack Construct
+//
+//------------------------------------------------------------------------------
+
+type BrokerProps struct{}
+
+type Broker struct {
+	constructs.Construct
+	Topic awssns.ITopic
+}
+
+func NewBroker(scope constructs.Construct, id *string, props *BrokerProps) *Broker {
+	broker := &Broker{Construct: constructs.NewConstruct(scope, id)}
+
+	return broker
+}
+
+// NewTopic creates SNS topic, the FIFO topic uses content-based deduplication.
+func (broker *Broker) NewTopic(props *awssns.TopicProps) awssns.ITopic {
+	if props == nil {
+		props = &awssns.TopicProps{}
+	}
+
+	if props.TopicName == nil {
+		props.TopicName = awscdk.Aws_STACK_NAME()
+	}
+
+	if aws.ToBool(props.Fifo) && props.ContentBasedDeduplication == nil {
+		props.ContentBasedDeduplication = jsii.Bool(true)
+	}
+
+	broker.Topic = awssns.NewTopic(broker.Construct, jsii.String("Topic"), props)
+
+	return broker.Topic
+}
+
+func (broker *Broker) AddTopic(topicArn *string) awssns.ITopic {
+	broker.Topic = awssns.Topic_FromTopicArn(broker.Construct, jsii.String("Topic"), topicArn)
+
+	return broker.Topic
+}
+
+func (broker *Broker) NewSink(props *SinkProps) *Sink {
+	if broker.Topic == nil {
+		panic("Topic is not defined.")
+	}
+
+	props.Topic = broker.Topic
+
+	name := props.Function.UniqueID()
+	sink := NewSink(broker.Construct, jsii.String(name), props)
+
+	return sink
+}
+
+// Grant permission to publish messages to the Topic.
+func (broker *Broker) GrantWrite(f awslambda.Function) {
+	broker.Topic.GrantPublish(f)
+
+	f.AddEnvironment(
+		jsii.String(EnvConfigTargetSNS),
+		broker.Topic.TopicArn(),
+		nil,
+	)
+}
@@ -0,0 +1,137 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package sns_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssns"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/fogfish/scud"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/broker/sns"
+)
+
+func TestSnsCDK(t *testing.T) {
+	app := awscdk.NewApp(nil)
+	stack := awscdk.NewStack(app, jsii.String("test"), &awscdk.StackProps{})
+
+	broker := sns.NewBroker(stack, jsii.String("Test"), nil)
+	broker.NewTopic(nil)
+
+	broker.NewSink(
+		&sns.SinkProps{
+			Agent:      jsii.String("test:agent"),
+			Categories: []string{"User", "Note"},
+			Function: &scud.FunctionGoProps{
+				SourceCodeModule: "github.com/fogfish/swarm/broker/sns",
+				SourceCodeLambda: "examples/dequeue/typed",
+				FunctionProps: &awslambda.FunctionProps{
+					Timeout: awscdk.Duration_Seconds(jsii.Number(30)),
+				},
+			},
+		},
+	)
+
+	require := map[*string]*float64{
+		jsii.String("AWS::SNS::Topic"):                 jsii.Number(1),
+		jsii.String("AWS::SNS::Subscription"):          jsii.Number(1),
+		jsii.String("AWS::SQS::Queue"):                 jsii.Number(1),
+		jsii.String("AWS::Lambda::EventSourceMapping"): jsii.Number(1),
+		jsii.String("AWS::Lambda::Function"):           jsii.Number(1),
+	}
+
+	template := assertions.Template_FromStack(stack, nil)
+	for key, val := range require {
+		template.ResourceCountIs(key, val)
+	}
+
+	template.HasResourceProperties(jsii.String("AWS::SNS::Subscription"), map[string]any{
+		"Protocol":           "sqs",
+		"RawMessageDelivery": true,
+		"FilterPolicy": map[string]any{
+			"Category": []any{"User", "Note"},
+		},
+	})
+
+	template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]any{
+		"Environment": map[string]any{
+			"Variables": map[string]any{
+				sns.EnvConfigSourceSNS:      assertions.Match_AnyValue(),
+				swarm.EnvConfigTimeToFlight: "30",
+				swarm.EnvConfigAgent:        "test:agent",
+			},
+		},
+	})
+}
+
+func TestSnsFifoCDK(t *testing.T) {
+	app := awscdk.NewApp(nil)
+	stack := awscdk.NewStack(app, jsii.String("test"), &awscdk.StackProps{})
+
+	broker := sns.NewBroker(stack, jsii.String("Test"), nil)
+	broker.NewTopic(&awssns.TopicProps{
+		TopicName: jsii.String("test.fifo"),
+		Fifo:      jsii.Bool(true),
+	})
+
+	broker.NewSink(
+		&sns.SinkProps{
+			Function: &scud.FunctionGoProps{
+				SourceCodeModule: "github.com/fogfish/swarm/broker/sns",
+				SourceCodeLambda: "examples/dequeue/typed",
+			},
+		},
+	)
+
+	template := assertions.Template_FromStack(stack, nil)
+
+	template.HasResourceProperties(jsii.String("AWS::SNS::Topic"), map[string]any{
+		"FifoTopic":                 true,
+		"ContentBasedDeduplication": true,
+	})
+
+	template.HasResourceProperties(jsii.String("AWS::SQS::Queue"), map[string]any{
+		"FifoQueue": true,
+	})
+}
+
+func TestGrantWrite(t *testing.T) {
+	app := awscdk.NewApp(nil)
+	stack := awscdk.NewStack(app, jsii.String("test"), &awscdk.StackProps{})
+
+	broker := sns.NewBroker(stack, jsii.String("Test"), nil)
+	broker.NewTopic(nil)
+
+	sink := broker.NewSink(
+		&sns.SinkProps{
+			Function: &scud.FunctionGoProps{
+				SourceCodeModule: "github.com/fogfish/swarm/broker/sns",
+				SourceCodeLambda: "examples/dequeue/typed",
+			},
+		},
+	)
+
+	broker.GrantWrite(sink.Handler)
+
+	template := assertions.Template_FromStack(stack, nil)
+
+	template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]any{
+		"Environment": map[string]any{
+			"Variables": map[string]any{
+				sns.EnvConfigTargetSNS: map[string]any{
+					"Ref": assertions.Match_AnyValue(),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,47 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package main
+
+import (
+	"os"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/fogfish/scud"
+	"github.com/fogfish/swarm/broker/sns"
+)
+
+func main() {
+	app := awscdk.NewApp(nil)
+	stack := awscdk.NewStack(app, jsii.String("swarm-example-sns"),
+		&awscdk.StackProps{
+			Env: &awscdk.Environment{
+				Account: jsii.String(os.Getenv("CDK_DEFAULT_ACCOUNT")),
+				Region:  jsii.String(os.Getenv("CDK_DEFAULT_REGION")),
+			},
+		},
+	)
+
+	//
+	broker := sns.NewBroker(stack, jsii.String("Broker"), nil)
+	broker.NewTopic(nil)
+
+	broker.NewSink(
+		&sns.SinkProps{
+			Agent:      jsii.String("sns:example/typed"),
+			Categories: []string{"User", "Note", "Like"},
+			Function: &scud.FunctionGoProps{
+				SourceCodeModule: "github.com/fogfish/swarm/broker/sns",
+				SourceCodeLambda: "examples/dequeue/typed",
+			},
+		},
+	)
+
+	app.Synth(nil)
+}
@@ -0,0 +1,20 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package sns
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// SNS declares the subset of interface from AWS SDK used by the lib.
+type SNS interface {
+	Publish(context.Context, *sns.PublishInput, ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
@@ -0,0 +1,169 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package sns
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+)
+
+// Limit of AWS SNS message size, it includes body and attributes.
+const MaxMessageSize = 256 * 1024
+
+// SNS client
+type Client struct {
+	service SNS
+	topic   string
+	isFIFO  bool
+	config  swarm.Config
+}
+
+func (cli *Client) Close() error {
+	return nil
+}
+
+// Enq enqueues message to broker
+func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
+	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
+	defer cancel()
+
+	// Note: FIFO topic requires content-based deduplication
+	var idMsgGroup *string
+	if cli.isFIFO {
+		idMsgGroup = aws.String(bag.Category)
+	}
+
+	attrs := map[string]types.MessageAttributeValue{
+		"Source":   {StringValue: aws.String(cli.config.Agent), DataType: aws.String("String")},
+		"Category": {StringValue: aws.String(bag.Category), DataType: aws.String("String")},
+	}
+
+	body := string(bag.Object)
+	if !swarm.IsText(bag.Object) {
+		body = base64.StdEncoding.EncodeToString(bag.Object)
+		attrs["Encoding"] = types.MessageAttributeValue{StringValue: aws.String(swarm.EncodingBase64), DataType: aws.String("String")}
+	}
+
+	if err := validate(body, attrs); err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	_, err := cli.service.Publish(ctx,
+		&sns.PublishInput{
+			MessageAttributes: attrs,
+			MessageGroupId:    idMsgGroup,
+			Message:           aws.String(body),
+			TopicArn:          aws.String(cli.topic),
+		},
+	)
+	if err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// bridge receives messages delivered by SNS subscription to SQS queue,
+// the queue is the event source of Lambda function.
+type bridge struct{ *kernel.Bridge }
+
+func (s bridge) Run(context.Context) { lambda.Start(s.run) }
+
+func (s bridge) run(ctx context.Context, events events.SQSEvent) error {
+	bag := make([]swarm.Bag, len(events.Records))
+	for i, evt := range events.Records {
+		bag[i] = unwrap(&evt)
+	}
+
+	return s.Bridge.Dispatch(ctx, bag)
+}
+
+// SNS wraps the message into JSON envelope unless raw message delivery
+// is enabled for the subscription.
+type envelope struct {
+	Type              string               `json:"Type"`
+	TopicArn          string               `json:"TopicArn"`
+	Message           string               `json:"Message"`
+	MessageAttributes map[string]attribute `json:"MessageAttributes"`
+}
+
+type attribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+func unwrap(msg *events.SQSMessage) swarm.Bag {
+	body := msg.Body
+	category := attr(msg, "Category")
+	encoding := attr(msg, "Encoding")
+
+	// Note: raw message delivery transfers SNS attributes as SQS attributes
+	if _, raw := msg.MessageAttributes["Category"]; !raw {
+		var env envelope
+		if err := json.Unmarshal([]byte(msg.Body), &env); err == nil && env.Type == "Notification" {
+			body = env.Message
+			category = env.MessageAttributes["Category"].Value
+			encoding = env.MessageAttributes["Encoding"].Value
+		}
+	}
+
+	bag := swarm.Bag{
+		Category: category,
+		Digest:   swarm.Digest(msg.ReceiptHandle),
+		Object:   []byte(body),
+	}
+
+	if encoding == swarm.EncodingBase64 {
+		bag.Object, bag.Error = base64.StdEncoding.DecodeString(body)
+	}
+
+	return bag
+}
+
+func attr(msg *events.SQSMessage, key string) string {
+	val, exists := msg.MessageAttributes[key]
+	if !exists {
+		return ""
+	}
+
+	return aws.ToString(val.StringValue)
+}
+
+//------------------------------------------------------------------------------
+
+// validates message against limits of AWS SNS before sending it
+func validate(body string, attrs map[string]types.MessageAttributeValue) error {
+	size := len(body)
+	for name, attr := range attrs {
+		size += len(name) + len(aws.ToString(attr.DataType)) + len(aws.ToString(attr.StringValue)) + len(attr.BinaryValue)
+	}
+
+	if size > MaxMessageSize {
+		return swarm.ErrTooLarge(size, MaxMessageSize)
+	}
+
+	return nil
+}
+
+// FIFO topic name has mandatory suffix
+func isFIFO(topic string) bool {
+	return strings.HasSuffix(topic, ".fifo")
+}
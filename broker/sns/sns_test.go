@@ -0,0 +1,347 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package sns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+)
+
+func TestReader(t *testing.T) {
+	var bag []swarm.Bag
+	cfg := swarm.NewConfig()
+	cfg.TimeToFlight = 100 * time.Millisecond
+	bridge := &bridge{kernel.NewBridge(cfg)}
+
+	t.Run("New", func(t *testing.T) {
+		q, err := Listener().Build()
+		it.Then(t).Should(it.Nil(err))
+		q.Close()
+	})
+
+	t.Run("Dequeue.Raw", func(t *testing.T) {
+		go func() {
+			bag, _ = bridge.Ask(context.Background())
+			for _, m := range bag {
+				bridge.Ack(context.Background(), m.Digest)
+			}
+		}()
+
+		err := bridge.run(context.Background(),
+			events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						MessageId:     "abc-def",
+						ReceiptHandle: "receipt",
+						Body:          `{"sut":"test"}`,
+						MessageAttributes: map[string]events.SQSMessageAttribute{
+							"Category": {StringValue: aws.String("cat")},
+						},
+					},
+				},
+			},
+		)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 1),
+			it.Equal(bag[0].Category, "cat"),
+			it.Equal(bag[0].Digest, "receipt"),
+			it.Equiv(bag[0].Object, []byte(`{"sut":"test"}`)),
+		)
+	})
+
+	t.Run("Dequeue.Envelope", func(t *testing.T) {
+		go func() {
+			bag, _ = bridge.Ask(context.Background())
+			for _, m := range bag {
+				bridge.Ack(context.Background(), m.Digest)
+			}
+		}()
+
+		err := bridge.run(context.Background(),
+			events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						MessageId:     "abc-def",
+						ReceiptHandle: "receipt",
+						Body: `{
+							"Type": "Notification",
+							"MessageId": "abc-def",
+							"TopicArn": "arn:aws:sns:eu-west-1:000000000000:test",
+							"Message": "{\"sut\":\"test\"}",
+							"MessageAttributes": {
+								"Category": {"Type": "String", "Value": "cat"}
+							}
+						}`,
+					},
+				},
+			},
+		)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 1),
+			it.Equal(bag[0].Category, "cat"),
+			it.Equal(bag[0].Digest, "receipt"),
+			it.Equiv(bag[0].Object, []byte(`{"sut":"test"}`)),
+		)
+	})
+
+	t.Run("Dequeue.Binary", func(t *testing.T) {
+		go func() {
+			bag, _ = bridge.Ask(context.Background())
+			for _, m := range bag {
+				bridge.Ack(context.Background(), m.Digest)
+			}
+		}()
+
+		err := bridge.run(context.Background(),
+			events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						MessageId:     "abc-def",
+						ReceiptHandle: "raw",
+						Body:          `AP/+`,
+						MessageAttributes: map[string]events.SQSMessageAttribute{
+							"Category": {StringValue: aws.String("cat")},
+							"Encoding": {StringValue: aws.String("base64")},
+						},
+					},
+					{
+						MessageId:     "abc-def",
+						ReceiptHandle: "envelope",
+						Body: `{
+							"Type": "Notification",
+							"Message": "AP/+",
+							"MessageAttributes": {
+								"Category": {"Type": "String", "Value": "cat"},
+								"Encoding": {"Type": "String", "Value": "base64"}
+							}
+						}`,
+					},
+				},
+			},
+		)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 2),
+			it.Equiv(bag[0].Object, []byte{0x00, 0xff, 0xfe}),
+			it.Equiv(bag[1].Object, []byte{0x00, 0xff, 0xfe}),
+		)
+	})
+
+	t.Run("Dequeue.Timeout", func(t *testing.T) {
+		go func() {
+			bag, _ = bridge.Ask(context.Background())
+		}()
+
+		err := bridge.run(context.Background(),
+			events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						MessageId:     "abc-def",
+						ReceiptHandle: "receipt",
+						Body:          `{"sut":"test"}`,
+						MessageAttributes: map[string]events.SQSMessageAttribute{
+							"Category": {StringValue: aws.String("cat")},
+						},
+					},
+				},
+			},
+		)
+
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	topic := "arn:aws:sns:eu-west-1:000000000000:test"
+
+	t.Run("New", func(t *testing.T) {
+		q, err := Emitter().Build(topic)
+		it.Then(t).Should(it.Nil(err))
+		q.Close()
+	})
+
+	t.Run("Enqueue", func(t *testing.T) {
+		mock := &mockSNS{}
+
+		q, err := Emitter().
+			WithKernel(swarm.WithAgent("test")).
+			WithService(mock).
+			Build(topic)
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte(`value`),
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.val.TopicArn, topic),
+			it.Equal(*mock.val.Message, "value"),
+			it.Equal(*mock.val.MessageAttributes["Category"].StringValue, "cat"),
+			it.Equal(*mock.val.MessageAttributes["Source"].StringValue, "test"),
+			it.True(mock.val.MessageGroupId == nil),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.Binary", func(t *testing.T) {
+		mock := &mockSNS{}
+
+		q, err := Emitter().WithService(mock).Build(topic)
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte{0x00, 0xff, 0xfe},
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.val.Message, "AP/+"),
+			it.Equal(*mock.val.MessageAttributes["Encoding"].StringValue, "base64"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.Noncharacter", func(t *testing.T) {
+		mock := &mockSNS{}
+
+		q, err := Emitter().WithService(mock).Build(topic)
+		it.Then(t).Should(it.Nil(err))
+
+		// U+FFFF is valid UTF-8 but not allowed in the message body
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte("\uffff"),
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.val.Message, "77+/"),
+			it.Equal(*mock.val.MessageAttributes["Encoding"].StringValue, "base64"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.FIFO", func(t *testing.T) {
+		mock := &mockSNS{}
+
+		q, err := Emitter().WithService(mock).Build(topic + ".fifo")
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte(`value`),
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.val.MessageGroupId, "cat"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.TooLarge", func(t *testing.T) {
+		mock := &mockSNS{}
+
+		q, err := Emitter().WithService(mock).Build(topic)
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte(strings.Repeat("x", MaxMessageSize)),
+			},
+		)
+
+		var e interface {
+			Size() int
+			Limit() int
+		}
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrEnqueue)),
+			it.True(errors.As(err, &e)),
+			it.Equal(e.Limit(), MaxMessageSize),
+			it.True(mock.val.Message == nil),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.ServiceError", func(t *testing.T) {
+		mock := &mockSNS{err: fmt.Errorf("network error")}
+
+		q, err := Emitter().WithService(mock).Build(topic)
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte(`value`),
+			},
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrEnqueue)),
+		)
+
+		q.Close()
+	})
+}
+
+func TestBroker(t *testing.T) {
+	t.Run("New", func(t *testing.T) {
+		q, err := Endpoint().Build("arn:aws:sns:eu-west-1:000000000000:test")
+		it.Then(t).Should(it.Nil(err))
+		q.Close()
+	})
+}
+
+//------------------------------------------------------------------------------
+
+type mockSNS struct {
+	SNS
+	val sns.PublishInput
+	err error
+}
+
+func (m *mockSNS) Publish(ctx context.Context, req *sns.PublishInput, opts ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.val = *req
+	return &sns.PublishOutput{MessageId: aws.String("abc-def")}, nil
+}
@@ -31,3 +31,5 @@ require (
 	github.com/fogfish/guid/v2 v2.1.0 // indirect
 	github.com/fogfish/logger/v3 v3.2.0 // indirect
 )
+
+replace github.com/fogfish/swarm => ../..
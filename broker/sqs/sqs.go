@@ -10,6 +10,7 @@ package sqs
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"time"
 
@@ -19,6 +20,9 @@ import (
 	"github.com/fogfish/swarm"
 )
 
+// Limit of AWS SQS message size, it includes body and attributes.
+const MaxMessageSize = 1024 * 1024
+
 type Client struct {
 	service     SQS
 	config      swarm.Config
@@ -42,15 +46,27 @@ func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
 		idMsgGroup = aws.String(bag.Category)
 	}
 
+	attrs := map[string]types.MessageAttributeValue{
+		"Source":   {StringValue: aws.String(cli.config.Agent), DataType: aws.String("String")},
+		"Category": {StringValue: aws.String(bag.Category), DataType: aws.String("String")},
+	}
+
+	body := string(bag.Object)
+	if !swarm.IsText(bag.Object) {
+		body = base64.StdEncoding.EncodeToString(bag.Object)
+		attrs["Encoding"] = types.MessageAttributeValue{StringValue: aws.String(swarm.EncodingBase64), DataType: aws.String("String")}
+	}
+
+	if err := validate(body, attrs); err != nil {
+		return swarm.ErrEnqueue.With(err)
+	}
+
 	_, err := cli.service.SendMessage(ctx,
 		&sqs.SendMessageInput{
-			MessageAttributes: map[string]types.MessageAttributeValue{
-				"Source":   {StringValue: aws.String(cli.config.Agent), DataType: aws.String("String")},
-				"Category": {StringValue: aws.String(bag.Category), DataType: aws.String("String")},
-			},
-			MessageGroupId: idMsgGroup,
-			MessageBody:    aws.String(string(bag.Object)),
-			QueueUrl:       cli.queue,
+			MessageAttributes: attrs,
+			MessageGroupId:    idMsgGroup,
+			MessageBody:       aws.String(body),
+			QueueUrl:          cli.queue,
 		},
 	)
 	if err != nil {
@@ -113,11 +129,29 @@ func (cli Client) Ask(ctx context.Context) ([]swarm.Bag, error) {
 			Digest:   swarm.Digest(aws.ToString(msg.ReceiptHandle)),
 			Object:   []byte(aws.ToString(msg.Body)),
 		}
+
+		if attr(&msg, "Encoding") == swarm.EncodingBase64 {
+			bag[i].Object, bag[i].Error = base64.StdEncoding.DecodeString(aws.ToString(msg.Body))
+		}
 	}
 
 	return bag, nil
 }
 
+// validates message against limits of AWS SQS before sending it
+func validate(body string, attrs map[string]types.MessageAttributeValue) error {
+	size := len(body)
+	for name, attr := range attrs {
+		size += len(name) + len(aws.ToString(attr.DataType)) + len(aws.ToString(attr.StringValue)) + len(attr.BinaryValue)
+	}
+
+	if size > MaxMessageSize {
+		return swarm.ErrTooLarge(size, MaxMessageSize)
+	}
+
+	return nil
+}
+
 func attr(msg *types.Message, key string) string {
 	val, exists := msg.MessageAttributes[key]
 	if !exists {
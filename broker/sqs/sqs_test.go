@@ -10,7 +10,9 @@ package sqs_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -71,6 +73,82 @@ func TestEnqueuer(t *testing.T) {
 
 		q.Close()
 	})
+
+	t.Run("Enqueue.Binary", func(t *testing.T) {
+		mock := &mockEnqueue{}
+		q, err := sqs.Emitter().
+			WithService(mock).
+			Build("test")
+
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte{0x00, 0xff, 0xfe},
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.req.MessageAttributes["Encoding"].StringValue, "base64"),
+			it.Equal(*mock.req.MessageBody, "AP/+"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.Noncharacter", func(t *testing.T) {
+		mock := &mockEnqueue{}
+		q, err := sqs.Emitter().
+			WithService(mock).
+			Build("test")
+
+		it.Then(t).Should(it.Nil(err))
+
+		// U+FFFF is valid UTF-8 but not allowed in the message body
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte("\uffff"),
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*mock.req.MessageAttributes["Encoding"].StringValue, "base64"),
+			it.Equal(*mock.req.MessageBody, "77+/"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.TooLarge", func(t *testing.T) {
+		mock := &mockEnqueue{}
+
+		q, err := sqs.Emitter().
+			WithService(mock).
+			Build("test")
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte(strings.Repeat("x", sqs.MaxMessageSize+1)),
+			},
+		)
+
+		var e interface {
+			Size() int
+			Limit() int
+		}
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Less(e.Limit(), e.Size()),
+			it.Equal(e.Limit(), sqs.MaxMessageSize),
+			it.True(mock.req == nil),
+		)
+
+		q.Close()
+	})
 }
 
 func TestDequeuer(t *testing.T) {
@@ -141,6 +219,38 @@ func TestDequeuer(t *testing.T) {
 			it.True(mock.req == nil),
 		)
 	})
+
+	t.Run("Dequeue.Binary", func(t *testing.T) {
+		mock := &mockDequeue{
+			msg: &types.Message{
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"Category": {StringValue: aws.String("test")},
+					"Encoding": {StringValue: aws.String("base64")},
+				},
+				Body:          aws.String(`AP/+`),
+				ReceiptHandle: aws.String(`1`),
+			},
+		}
+
+		q, err := sqs.Listener().WithService(mock).Build("test")
+		it.Then(t).Should(it.Nil(err))
+
+		var msg swarm.Msg[[]byte]
+		rcv, ack := dequeue.Bytes(q, encoding.ForBytes("test"))
+		go func() {
+			msg = <-rcv
+			ack <- msg
+
+			time.Sleep(5 * time.Millisecond)
+			q.Close()
+		}()
+
+		q.Await()
+
+		it.Then(t).Should(
+			it.Seq(msg.Object).Equal(0x00, 0xff, 0xfe),
+		)
+	})
 }
 
 func TestBuilder(t *testing.T) {
@@ -270,6 +380,7 @@ func (m *mockEnqueue) SendMessage(ctx context.Context, req *awssqs.SendMessageIn
 
 type mockDequeue struct {
 	sqs.SQS
+	msg *types.Message
 	req *awssqs.DeleteMessageInput
 }
 
@@ -280,6 +391,10 @@ func (m *mockDequeue) GetQueueUrl(ctx context.Context, req *awssqs.GetQueueUrlIn
 }
 
 func (m *mockDequeue) ReceiveMessage(ctx context.Context, req *awssqs.ReceiveMessageInput, opts ...func(*awssqs.Options)) (*awssqs.ReceiveMessageOutput, error) {
+	if m.msg != nil {
+		return &awssqs.ReceiveMessageOutput{Messages: []types.Message{*m.msg}}, nil
+	}
+
 	return &awssqs.ReceiveMessageOutput{
 		Messages: []types.Message{
 			{
@@ -20,6 +20,9 @@ import (
 	"github.com/fogfish/swarm/kernel"
 )
 
+// Limit of AWS API Gateway WebSocket message size.
+const MaxMessageSize = 128 * 1024
+
 type Client struct {
 	service Gateway
 	config  swarm.Config
@@ -34,6 +37,10 @@ func (cli *Client) Enq(ctx context.Context, bag swarm.Bag) error {
 	ctx, cancel := context.WithTimeout(ctx, cli.config.NetworkTimeout)
 	defer cancel()
 
+	if size := len(bag.Object); size > MaxMessageSize {
+		return swarm.ErrEnqueue.With(swarm.ErrTooLarge(size, MaxMessageSize))
+	}
+
 	_, err := cli.service.PostToConnection(ctx,
 		&apigatewaymanagementapi.PostToConnectionInput{
 			ConnectionId: aws.String(bag.Category),
@@ -10,6 +10,8 @@ package websocket
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -123,6 +125,33 @@ func TestEnqueuer(t *testing.T) {
 
 		q.Close()
 	})
+
+	t.Run("Enqueue.TooLarge", func(t *testing.T) {
+		mock := &mockGateway{}
+
+		q, err := Emitter().WithService(mock).Build("test")
+		it.Then(t).Should(it.Nil(err))
+
+		err = q.Emitter.Enq(context.Background(),
+			swarm.Bag{
+				Category: "cat",
+				Object:   []byte(strings.Repeat("x", MaxMessageSize+1)),
+			},
+		)
+
+		var e interface {
+			Size() int
+			Limit() int
+		}
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Less(e.Limit(), e.Size()),
+			it.Equal(e.Limit(), MaxMessageSize),
+			it.True(mock.req == nil),
+		)
+
+		q.Close()
+	})
 }
 
 //------------------------------------------------------------------------------
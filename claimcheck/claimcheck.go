@@ -0,0 +1,182 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package claimcheck implements claim-check pattern for oversized payloads.
+// Large payloads are stored in the blob store, the message only carries
+// the reference (claim) to the payload. The listener transparently rehydrates
+// the payload on receive.
+package claimcheck
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+)
+
+const (
+	ErrStorePut    = faults.Type("claim-check failed to store payload")
+	ErrStoreGet    = faults.Type("claim-check failed to fetch payload")
+	ErrStoreRemove = faults.Type("claim-check failed to remove payload")
+)
+
+// Store is the blob store of payloads
+type Store interface {
+	Put(ctx context.Context, key string, val []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Remove(ctx context.Context, key string) error
+}
+
+// The claim is marked with magic prefix followed by the key of payload
+var magicClaim = []byte{0xff, 's', 'w', 'c'}
+
+func claim(key string) []byte {
+	return append(append([]byte{}, magicClaim...), key...)
+}
+
+func unclaim(obj []byte) (string, bool) {
+	if !bytes.HasPrefix(obj, magicClaim) {
+		return "", false
+	}
+
+	return string(obj[len(magicClaim):]), true
+}
+
+//------------------------------------------------------------------------------
+
+type emitter struct {
+	kernel.Emitter
+	store     Store
+	threshold int
+}
+
+// Emitter wraps the emitter kernel with claim-check, payloads above the threshold
+// are stored in the blob store, the message carries the reference only.
+// It must be called before any channel is created for the kernel.
+//
+//	q := claimcheck.Emitter(sqs.Must(sqs.Emitter().Build(queue)), store, 200*1024)
+//	enq, dlq := emit.Typed[User](q)
+func Emitter(q *kernel.EmitterIO, store Store, threshold int) *kernel.EmitterIO {
+	q.Emitter = &emitter{
+		Emitter:   q.Emitter,
+		store:     store,
+		threshold: threshold,
+	}
+
+	return q
+}
+
+func (e *emitter) Enq(ctx context.Context, bag swarm.Bag) error {
+	if len(bag.Object) <= e.threshold {
+		return e.Emitter.Enq(ctx, bag)
+	}
+
+	key := bag.Category + "/" + guid.G(guid.Clock).String()
+	if err := e.store.Put(ctx, key, bag.Object); err != nil {
+		return ErrStorePut.With(err)
+	}
+
+	bag.Object = claim(key)
+	return e.Emitter.Enq(ctx, bag)
+}
+
+//------------------------------------------------------------------------------
+
+type listener struct {
+	kernel.Listener
+	sync.Mutex
+	store       Store
+	deleteOnAck bool
+	claims      map[swarm.Digest]string
+}
+
+// Listener wraps the listener kernel with claim-check, it rehydrates payloads
+// from the blob store. The payload is removed from the store after
+// the message is acknowledged if deleteOnAck is set.
+// It must be called before the kernel is awaited.
+//
+//	q := claimcheck.Listener(sqs.Must(sqs.Listener().Build(queue)), store, true)
+//	deq, ack := listen.Typed[User](q)
+func Listener(q *kernel.ListenerIO, store Store, deleteOnAck bool) *kernel.ListenerIO {
+	l := &listener{
+		Listener:    q.Listener,
+		store:       store,
+		deleteOnAck: deleteOnAck,
+		claims:      make(map[swarm.Digest]string),
+	}
+
+	q.Listener = kernel.Decorate(q.Listener, l)
+
+	return q
+}
+
+// Ask rehydrates payloads of received messages, the message is failed
+// individually if its payload is not fetched from the store.
+func (l *listener) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	seq, err := l.Listener.Ask(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(seq); i++ {
+		key, has := unclaim(seq[i].Object)
+		if !has {
+			continue
+		}
+
+		obj, err := l.store.Get(ctx, key)
+		if err != nil {
+			seq[i].Error = ErrStoreGet.With(err)
+			continue
+		}
+		seq[i].Object = obj
+
+		if l.deleteOnAck {
+			l.Lock()
+			l.claims[seq[i].Digest] = key
+			l.Unlock()
+		}
+	}
+
+	return seq, nil
+}
+
+func (l *listener) Ack(ctx context.Context, digest swarm.Digest) error {
+	if err := l.Listener.Ack(ctx, digest); err != nil {
+		return err
+	}
+
+	key, has := l.release(digest)
+	if !has {
+		return nil
+	}
+
+	if err := l.store.Remove(ctx, key); err != nil {
+		return ErrStoreRemove.With(err)
+	}
+
+	return nil
+}
+
+func (l *listener) Err(ctx context.Context, digest swarm.Digest, err error) error {
+	l.release(digest)
+	return l.Listener.Err(ctx, digest, err)
+}
+
+func (l *listener) release(digest swarm.Digest) (string, bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	key, has := l.claims[digest]
+	delete(l.claims, digest)
+	return key, has
+}
@@ -0,0 +1,147 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package claimcheck_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/claimcheck"
+	"github.com/fogfish/swarm/kernel"
+)
+
+func TestClaimCheck(t *testing.T) {
+	ctx := context.Background()
+	large := []byte(strings.Repeat("x", 1024))
+
+	for _, deleteOnAck := range []bool{false, true} {
+		t.Run(fmt.Sprintf("DeleteOnAck=%v", deleteOnAck), func(t *testing.T) {
+			store, err := claimcheck.NewFileStore(t.TempDir())
+			it.Then(t).Should(it.Nil(err))
+
+			mock := &mockBroker{}
+			emitter := claimcheck.Emitter(kernel.NewEmitter(mock, swarm.Config{}), store, 256)
+			listener := claimcheck.Listener(kernel.NewListener(mock, swarm.Config{}), store, deleteOnAck)
+
+			t.Run("Small", func(t *testing.T) {
+				err := emitter.Emitter.Enq(ctx, swarm.Bag{Category: "test", Digest: "1", Object: []byte("small")})
+				it.Then(t).Should(
+					it.Nil(err),
+					it.Equiv(mock.bag.Object, []byte("small")),
+				)
+
+				seq, err := listener.Listener.Ask(ctx)
+				it.Then(t).Should(
+					it.Nil(err),
+					it.Equiv(seq[0].Object, []byte("small")),
+				)
+			})
+
+			t.Run("Large", func(t *testing.T) {
+				err := emitter.Emitter.Enq(ctx, swarm.Bag{Category: "test", Digest: "2", Object: large})
+				it.Then(t).Should(
+					it.Nil(err),
+					it.True(bytes.HasPrefix(mock.bag.Object, []byte{0xff, 's', 'w', 'c'})),
+					it.Less(len(mock.bag.Object), 256),
+				)
+				key := string(mock.bag.Object[4:])
+
+				seq, err := listener.Listener.Ask(ctx)
+				it.Then(t).Should(
+					it.Nil(err),
+					it.Equiv(seq[0].Object, large),
+				)
+
+				err = listener.Listener.Ack(ctx, seq[0].Digest)
+				it.Then(t).Should(it.Nil(err))
+
+				_, err = store.Get(ctx, key)
+				it.Then(t).Should(
+					it.Equal(errors.Is(err, fs.ErrNotExist), deleteOnAck),
+				)
+			})
+		})
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		store, err := claimcheck.NewFileStore(t.TempDir())
+		it.Then(t).Should(it.Nil(err))
+
+		mock := &mockBroker{bag: swarm.Bag{Category: "test", Object: []byte("\xffswctest/unknown")}}
+		listener := claimcheck.Listener(kernel.NewListener(mock, swarm.Config{}), store, true)
+
+		seq, err := listener.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.True(errors.Is(seq[0].Error, claimcheck.ErrStoreGet)),
+		)
+	})
+
+	t.Run("Spawner", func(t *testing.T) {
+		store, err := claimcheck.NewFileStore(t.TempDir())
+		it.Then(t).Should(it.Nil(err))
+
+		mock := &mockSpawner{run: make(chan struct{})}
+		listener := claimcheck.Listener(kernel.NewListener(mock, swarm.Config{}), store, true)
+
+		spawner, ok := listener.Listener.(interface{ Run(context.Context) })
+		it.Then(t).Should(it.True(ok))
+
+		go spawner.Run(ctx)
+		<-mock.run
+	})
+
+	t.Run("NotSpawner", func(t *testing.T) {
+		store, err := claimcheck.NewFileStore(t.TempDir())
+		it.Then(t).Should(it.Nil(err))
+
+		listener := claimcheck.Listener(kernel.NewListener(&mockBroker{}, swarm.Config{}), store, true)
+
+		_, ok := listener.Listener.(interface{ Run(context.Context) })
+		it.Then(t).Should(it.True(!ok))
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// loopback broker, the listener receives the last emitted bag
+type mockBroker struct {
+	bag swarm.Bag
+}
+
+func (m *mockBroker) Enq(ctx context.Context, bag swarm.Bag) error {
+	m.bag = bag
+	return nil
+}
+
+func (m *mockBroker) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	return []swarm.Bag{m.bag}, nil
+}
+
+func (m *mockBroker) Ack(ctx context.Context, digest swarm.Digest) error { return nil }
+
+func (m *mockBroker) Err(ctx context.Context, digest swarm.Digest, err error) error { return nil }
+
+func (m *mockBroker) Close() error { return nil }
+
+// serverless broker, it is spawned by the kernel
+type mockSpawner struct {
+	mockBroker
+	run chan struct{}
+}
+
+func (m *mockSpawner) Run(ctx context.Context) { close(m.run) }
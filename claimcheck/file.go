@@ -0,0 +1,92 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package claimcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore is the blob store on the local file system
+type FileStore struct{ root *os.Root }
+
+var _ Store = (*FileStore)(nil)
+
+// Creates blob store at the directory of the local file system
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{root: root}, nil
+}
+
+func (s *FileStore) Put(_ context.Context, key string, val []byte) error {
+	if err := s.mkdirAll(filepath.Dir(key)); err != nil {
+		return err
+	}
+
+	f, err := s.root.OpenFile(key, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(val); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (s *FileStore) Get(_ context.Context, key string) ([]byte, error) {
+	f, err := s.root.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// creates directory of the key and its parents within the root
+func (s *FileStore) mkdirAll(dir string) error {
+	if dir == "." {
+		return nil
+	}
+
+	path := ""
+	for _, seg := range strings.Split(filepath.ToSlash(dir), "/") {
+		path = filepath.Join(path, seg)
+		if err := s.root.Mkdir(path, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *FileStore) Remove(_ context.Context, key string) error {
+	err := s.root.Remove(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,78 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package s3 implements claim-check blob store using AWS S3.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/fogfish/swarm/claimcheck"
+)
+
+// S3 declares the subset of AWS S3 api used by the blob store
+type S3 interface {
+	PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(context.Context, *s3.DeleteObjectInput, ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// Store is the blob store at AWS S3 bucket
+type Store struct {
+	service S3
+	bucket  string
+}
+
+var _ claimcheck.Store = (*Store)(nil)
+
+// Creates blob store at AWS S3 bucket.
+//
+//	store := s3.New(awss3.NewFromConfig(cfg), "my-bucket")
+func New(service S3, bucket string) *Store {
+	return &Store{service: service, bucket: bucket}
+}
+
+func (s *Store) Put(ctx context.Context, key string, val []byte) error {
+	_, err := s.service.PutObject(ctx,
+		&s3.PutObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(val),
+		},
+	)
+	return err
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.service.GetObject(ctx,
+		&s3.GetObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+func (s *Store) Remove(ctx context.Context, key string) error {
+	_, err := s.service.DeleteObject(ctx,
+		&s3.DeleteObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		},
+	)
+	return err
+}
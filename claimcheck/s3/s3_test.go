@@ -0,0 +1,68 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm/claimcheck/s3"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	mock := &mockS3{}
+	store := s3.New(mock, "bucket")
+
+	err := store.Put(ctx, "test/key", []byte("value"))
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(*mock.put.Bucket, "bucket"),
+		it.Equal(*mock.put.Key, "test/key"),
+	)
+
+	val, err := store.Get(ctx, "test/key")
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equiv(val, []byte("value")),
+	)
+
+	err = store.Remove(ctx, "test/key")
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(*mock.del.Key, "test/key"),
+	)
+}
+
+//------------------------------------------------------------------------------
+
+type mockS3 struct {
+	put *awss3.PutObjectInput
+	del *awss3.DeleteObjectInput
+	val []byte
+}
+
+func (m *mockS3) PutObject(ctx context.Context, req *awss3.PutObjectInput, opts ...func(*awss3.Options)) (*awss3.PutObjectOutput, error) {
+	m.put = req
+	m.val, _ = io.ReadAll(req.Body)
+	return &awss3.PutObjectOutput{}, nil
+}
+
+func (m *mockS3) GetObject(ctx context.Context, req *awss3.GetObjectInput, opts ...func(*awss3.Options)) (*awss3.GetObjectOutput, error) {
+	return &awss3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(m.val))}, nil
+}
+
+func (m *mockS3) DeleteObject(ctx context.Context, req *awss3.DeleteObjectInput, opts ...func(*awss3.Options)) (*awss3.DeleteObjectOutput, error) {
+	m.del = req
+	return &awss3.DeleteObjectOutput{}, nil
+}
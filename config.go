@@ -76,6 +76,25 @@ type Config struct {
 
 	// Fail fast the message if category is not known to kernel.
 	FailOnUnknownCategory bool
+
+	// Time To Live is a time the emitted message remains valid.
+	// Brokers map it to native message expiry where available.
+	TimeToLive time.Duration
+
+	// Max age of the event, counted from Meta.Created. Listener acknowledges
+	// and drops expired events without delivering them to the application.
+	MaxEventAge time.Duration
+
+	// Handler of expired events, it is called before the event is dropped.
+	ExpiredHandler func(Bag)
+
+	// Realms accepted by listener in addition to the kernel's own realm.
+	AcceptRealms []string
+
+	// JSON path (dot separated) to the category within the message payload.
+	// Listener routes messages using the category from payload, falling back
+	// to the category defined by broker if the path is not found.
+	CategoryPath string
 }
 
 func NewConfig() Config {
@@ -173,6 +192,22 @@ var (
 
 	// Fail fast the message if category is not known to kernel.
 	WithFailOnUnknownCategory = opts.ForName[Config, bool]("FailOnUnknownCategory")
+
+	// Time To Live for emitted messages, brokers map it to native message
+	// expiry where available.
+	WithTimeToLive = opts.ForName[Config, time.Duration]("TimeToLive")
+
+	// Max age of received events. Events older than given duration are
+	// acknowledged and dropped by the listener.
+	WithMaxEventAge = opts.ForName[Config, time.Duration]("MaxEventAge")
+
+	// Divert expired events to the handler before they are dropped.
+	WithExpiredHandler = opts.ForName[Config, func(Bag)]("ExpiredHandler")
+
+	// Read category of received messages from the payload, using JSON path
+	// (dot separated, e.g. "meta.type"). Useful for messages of foreign
+	// producers that do not define category in broker's attributes.
+	WithCategoryFromPayload = opts.ForName[Config, string]("CategoryPath")
 )
 
 // Configure broker to log standard errors
@@ -194,6 +229,17 @@ func WithLogStdErr() opts.Option[Config] {
 	)
 }
 
+// Configure listener to accept events from the list of realms in addition to
+// the kernel's own realm. Events of other realms are acknowledged and dropped.
+func WithAcceptRealms(realms ...string) opts.Option[Config] {
+	return opts.Type[Config](
+		func(c *Config) error {
+			c.AcceptRealms = append(c.AcceptRealms, realms...)
+			return nil
+		},
+	)
+}
+
 // Configure from Environment, (all timers in seconds)
 // - CONFIG_SWARM_REALM
 // - CONFIG_SWARM_AGENT
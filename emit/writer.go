@@ -52,6 +52,8 @@ func (q *EmitterTyped[T]) Enq(ctx context.Context, object T, cat ...string) erro
 		bag.Category = cat[0]
 	}
 
+	q.kernel.StampTimeToLive(&bag)
+
 	err = q.kernel.Emitter.Enq(ctx, bag)
 	if err != nil {
 		return err
@@ -98,6 +100,8 @@ func (q *EmitterEvent[M, T]) Enq(ctx context.Context, object swarm.Event[M, T],
 		bag.Category = cat[0]
 	}
 
+	q.kernel.StampTimeToLive(&bag)
+
 	err = q.kernel.Emitter.Enq(ctx, bag)
 	if err != nil {
 		return err
@@ -137,6 +141,8 @@ func (q *EmitterBytes) Enq(ctx context.Context, object []byte, cat ...string) er
 		bag.Category = cat[0]
 	}
 
+	q.kernel.StampTimeToLive(&bag)
+
 	err = q.kernel.Emitter.Enq(ctx, bag)
 	if err != nil {
 		return err
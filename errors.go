@@ -23,6 +23,9 @@ const (
 	ErrDecoder    = faults.Type("decoder failure")
 	ErrRouting    = faults.Safe1[string]("routing has failed (cat %s)")
 	ErrCatUnknown = faults.Safe1[string]("unknown category %s")
+	ErrExpired    = faults.Safe1[string]("message is expired (cat %s)")
+	ErrIsolated   = faults.Safe1[string]("message belongs to other realm or target (cat %s)")
+	ErrInvalid    = faults.Safe1[string]("message is invalid (cat %s)")
 )
 
 type errTimeout struct {
@@ -44,3 +47,29 @@ func (err errTimeout) Error() string {
 func (err errTimeout) Timeout() time.Duration {
 	return err.timer
 }
+
+type errTooLarge struct {
+	size  int
+	limit int
+}
+
+// ErrTooLarge is returned by broker if the message exceeds its payload limit.
+// The error is not retryable.
+func ErrTooLarge(size, limit int) error {
+	return errTooLarge{
+		size:  size,
+		limit: limit,
+	}
+}
+
+func (err errTooLarge) Error() string {
+	return fmt.Sprintf("message size %d bytes exceeds limit %d bytes", err.size, err.limit)
+}
+
+// Actual size of the message
+func (err errTooLarge) Size() int { return err.size }
+
+// Maximum size of the message supported by broker
+func (err errTooLarge) Limit() int { return err.limit }
+
+func (err errTooLarge) NotRetryable() bool { return true }
@@ -9,9 +9,12 @@
 package swarm
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/fogfish/curie/v2"
+	"github.com/fogfish/golem/hseq"
+	"github.com/fogfish/golem/optics"
 )
 
 // Event defines immutable fact(s) placed into the queueing system.
@@ -66,6 +69,11 @@ type Meta struct {
 	// defined by sender.
 	Type curie.IRI `json:"type,omitempty"`
 
+	//
+	// Schema version of the event's data.
+	// It is automatically defined by the versioned codec upon the transmission.
+	Version int `json:"version,omitempty"`
+
 	// Unique identity of the realm (logical environment or world) where the event was created.
 	// Useful to support deployment isolation (e.g., green/blue, canary) in event-driven systems.
 	Realm curie.IRI `json:"realm,omitempty"`
@@ -96,3 +104,19 @@ func ToEvent[M, T any](bag Bag, evt Event[M, T]) Event[M, T] {
 	evt.IOContext = bag.IOContext
 	return evt
 }
+
+// LensMaybe creates lens to the optional attribute of metadata type M, it is nil
+// if M is not a struct or does not define the attribute of type A. The kernel
+// and codecs use it for well-known attributes of custom metadata types.
+func LensMaybe[M, A any](attr string) optics.Lens[M, A] {
+	if reflect.TypeOf(new(M)).Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	t, has := hseq.ForNameMaybe(hseq.New[M](), attr)
+	if !has || t.Type != reflect.TypeOf(new(A)).Elem() {
+		return nil
+	}
+
+	return optics.NewLens[M, A](t)
+}
@@ -0,0 +1,141 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package forward connects listener of one broker with emitter of another one.
+// Messages are forwarded as raw bytes, the source message is acknowledged
+// only after it is successfully emitted to the target broker.
+package forward
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+const ErrTransform = faults.Type("forward transform has failed")
+
+// Transform of the message before it is forwarded
+type Transform func(swarm.Bag) (swarm.Bag, error)
+
+// Route of messages, binds category at source broker with the category at target one.
+type Route struct {
+	Source    string
+	Target    string
+	Transform Transform
+}
+
+// Category of messages forwarded as-is
+func Category(cat string) Route { return Route{Source: cat} }
+
+// Rename category of messages on the way to the target broker
+func Rename(source, target string) Route { return Route{Source: source, Target: target} }
+
+// Map messages using the transform function
+func (r Route) Map(f Transform) Route {
+	r.Transform = f
+	return r
+}
+
+// Forward connects the listener with the emitter for the given routes.
+// It must be called before the listener is awaited. Forwarding stops when
+// the listener is closed, the messages in-flight are not acknowledged.
+//
+//	from := sqs.Must(sqs.Listener().Build(queue))
+//	to := eventbridge.Must(eventbridge.Emitter().Build(bus))
+//	forward.Forward(from, to,
+//		forward.Category("User"),
+//		forward.Rename("Note", "Memo"),
+//	)
+//	from.Await()
+func Forward(from *kernel.ListenerIO, to *kernel.EmitterIO, routes ...Route) {
+	ctx := from.Context()
+
+	for _, route := range routes {
+		rcv, ack := kernel.RecvChan(from, encoding.ForBytes(route.Source))
+
+		from.WaitGroup.Add(1)
+		go func() {
+			defer from.WaitGroup.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-rcv:
+					if err := forward(ctx, to, route, msg); err != nil {
+						msg.Error = err
+					}
+
+					// Note: the kernel closes ack channel on shutdown
+					if ctx.Err() != nil {
+						return
+					}
+					ack <- msg
+				}
+			}
+		}()
+	}
+}
+
+func forward(ctx context.Context, to *kernel.EmitterIO, route Route, msg swarm.Msg[[]byte]) error {
+	bag := swarm.Bag{
+		Category:  msg.Category,
+		Digest:    msg.Digest,
+		IOContext: msg.IOContext,
+		Object:    msg.Object,
+	}
+
+	if route.Target != "" {
+		bag.Category = route.Target
+	}
+
+	if route.Transform != nil {
+		var err error
+		bag, err = route.Transform(bag)
+		if err != nil {
+			return report(to, bag, ErrTransform.With(err))
+		}
+	}
+
+	out := swarm.Bag{
+		Category: bag.Category,
+		Expires:  bag.Expires,
+		Object:   bag.Object,
+	}
+	to.StampTimeToLive(&out)
+
+	err := to.Config.Backoff.Retry(func() error {
+		return to.Emitter.Enq(ctx, out)
+	})
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+	if err != nil {
+		return report(to, bag, swarm.ErrEnqueue.With(err))
+	}
+
+	return nil
+}
+
+func report(to *kernel.EmitterIO, bag swarm.Bag, err error) error {
+	slog.Debug("forward failed to send message",
+		slog.Any("cat", bag.Category),
+		slog.Any("bag", bag),
+		slog.Any("err", err),
+	)
+
+	if to.Config.StdErr != nil {
+		to.Config.StdErr <- err
+	}
+
+	return err
+}
@@ -0,0 +1,216 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package forward_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/forward"
+	"github.com/fogfish/swarm/kernel"
+)
+
+func TestForward(t *testing.T) {
+	cfg := swarm.NewConfig()
+	cfg.PollFrequency = 1 * time.Millisecond
+
+	t.Run("Category", func(t *testing.T) {
+		from := mockListener(swarm.Bag{Category: "User", Digest: "1", Object: []byte(`{"id":"1"}`)})
+		to := mockEmitter(nil)
+
+		q := kernel.NewListener(from, cfg)
+		forward.Forward(q, kernel.NewEmitter(to, cfg), forward.Category("User"))
+		go q.Await()
+
+		bag := <-to.bag
+		it.Then(t).Should(
+			it.Equal(bag.Category, "User"),
+			it.Equal(bag.Digest, ""),
+			it.Equal(string(bag.Object), `{"id":"1"}`),
+			it.Equal(<-from.ack, "ack 1"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		from := mockListener(swarm.Bag{Category: "User", Digest: "1", Object: []byte(`{"id":"1"}`)})
+		to := mockEmitter(nil)
+
+		q := kernel.NewListener(from, cfg)
+		forward.Forward(q, kernel.NewEmitter(to, cfg), forward.Rename("User", "Person"))
+		go q.Await()
+
+		bag := <-to.bag
+		it.Then(t).Should(
+			it.Equal(bag.Category, "Person"),
+			it.Equal(<-from.ack, "ack 1"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Transform", func(t *testing.T) {
+		from := mockListener(swarm.Bag{Category: "User", Digest: "1", Object: []byte(`user`)})
+		to := mockEmitter(nil)
+
+		q := kernel.NewListener(from, cfg)
+		forward.Forward(q, kernel.NewEmitter(to, cfg),
+			forward.Category("User").Map(
+				func(bag swarm.Bag) (swarm.Bag, error) {
+					bag.Object = bytes.ToUpper(bag.Object)
+					return bag, nil
+				},
+			),
+		)
+		go q.Await()
+
+		bag := <-to.bag
+		it.Then(t).Should(
+			it.Equal(string(bag.Object), `USER`),
+			it.Equal(<-from.ack, "ack 1"),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Transform.Failed", func(t *testing.T) {
+		from := mockListener(swarm.Bag{Category: "User", Digest: "1", Object: []byte(`user`)})
+		to := mockEmitter(nil)
+
+		q := kernel.NewListener(from, cfg)
+		forward.Forward(q, kernel.NewEmitter(to, cfg),
+			forward.Category("User").Map(
+				func(bag swarm.Bag) (swarm.Bag, error) {
+					return bag, errors.New("failed")
+				},
+			),
+		)
+		go q.Await()
+
+		it.Then(t).Should(
+			it.Equal(<-from.ack, "err 1"),
+			it.True(errors.Is(<-from.err, forward.ErrTransform)),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Enqueue.Failed", func(t *testing.T) {
+		from := mockListener(swarm.Bag{Category: "User", Digest: "1", Object: []byte(`user`)})
+		to := mockEmitter(errors.New("failed"))
+
+		q := kernel.NewListener(from, cfg)
+		forward.Forward(q, kernel.NewEmitter(to, cfg), forward.Category("User"))
+		go q.Await()
+
+		it.Then(t).Should(
+			it.Equal(<-from.ack, "err 1"),
+			it.True(errors.Is(<-from.err, swarm.ErrEnqueue)),
+		)
+
+		q.Close()
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		from := mockListener(swarm.Bag{Category: "User", Digest: "1", Object: []byte(`user`)})
+		to := mockEmitter(nil)
+		to.block = make(chan struct{})
+
+		q := kernel.NewListener(from, cfg)
+		forward.Forward(q, kernel.NewEmitter(to, cfg), forward.Category("User"))
+		go q.Await()
+
+		<-to.block
+		q.Close()
+
+		select {
+		case ack := <-from.ack:
+			t.Errorf("unexpected %s of in-flight message", ack)
+		default:
+		}
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// listener delivers the bag once
+type listener struct {
+	sync.Mutex
+	seq []swarm.Bag
+	ack chan string
+	err chan error
+}
+
+func mockListener(bag swarm.Bag) *listener {
+	return &listener{
+		seq: []swarm.Bag{bag},
+		ack: make(chan string, 1),
+		err: make(chan error, 1),
+	}
+}
+
+func (l *listener) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	seq := l.seq
+	l.seq = nil
+	return seq, nil
+}
+
+func (l *listener) Ack(ctx context.Context, digest swarm.Digest) error {
+	l.ack <- "ack " + string(digest)
+	return nil
+}
+
+func (l *listener) Err(ctx context.Context, digest swarm.Digest, err error) error {
+	l.ack <- "err " + string(digest)
+	l.err <- err
+	return nil
+}
+
+func (l *listener) Close() error { return nil }
+
+type emitter struct {
+	bag   chan swarm.Bag
+	fail  error
+	block chan struct{}
+}
+
+func mockEmitter(fail error) *emitter {
+	return &emitter{
+		bag:  make(chan swarm.Bag, 1),
+		fail: fail,
+	}
+}
+
+func (e *emitter) Enq(ctx context.Context, bag swarm.Bag) error {
+	if e.fail != nil {
+		return e.fail
+	}
+
+	// blocks until kernel is closed
+	if e.block != nil {
+		close(e.block)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	e.bag <- bag
+	return nil
+}
+
+func (e *emitter) Close() error { return nil }
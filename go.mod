@@ -5,10 +5,9 @@ go 1.24
 require (
 	github.com/fogfish/curie/v2 v2.1.2
 	github.com/fogfish/faults v0.3.2
+	github.com/fogfish/golem/hseq v1.3.0
 	github.com/fogfish/golem/optics v0.14.0
 	github.com/fogfish/guid/v2 v2.1.0
 	github.com/fogfish/it/v2 v2.2.2
 	github.com/fogfish/opts v0.0.5
 )
-
-require github.com/fogfish/golem/hseq v1.3.0 // indirect
@@ -9,6 +9,7 @@
 package backoff
 
 import (
+	"errors"
 	"math/rand"
 	"time"
 )
@@ -90,10 +91,10 @@ func (seq Seq) Deadline(t time.Duration) Seq {
 	}
 }
 
-// Retry function
+// Retry function, errors that are not retryable fail fast.
 func (seq Seq) Retry(f func() error) (err error) {
 	for _, t := range seq() {
-		if err = f(); err == nil {
+		if err = f(); err == nil || !isRetryable(err) {
 			return
 		}
 		time.Sleep(t)
@@ -101,6 +102,12 @@ func (seq Seq) Retry(f func() error) (err error) {
 	return
 }
 
+// The error is not retryable if it declares NotRetryable behavior
+func isRetryable(err error) bool {
+	var e interface{ NotRetryable() bool }
+	return !errors.As(err, &e) || !e.NotRetryable()
+}
+
 // None is empty delay sequence
 type None int
 
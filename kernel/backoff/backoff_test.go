@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
 	"github.com/fogfish/swarm/kernel/backoff"
 )
 
@@ -90,3 +91,20 @@ func TestRetryFail(t *testing.T) {
 		it.Equal(n, 3),
 	)
 }
+
+func TestRetryNotRetryable(t *testing.T) {
+	n := 0
+
+	err := backoff.Const(1*time.Millisecond, 3).Retry(
+		func() error {
+			n = n + 1
+			return swarm.ErrEnqueue.With(swarm.ErrTooLarge(2048, 1024))
+		},
+	)
+
+	it.Then(t).ShouldNot(
+		it.Nil(err),
+	).Should(
+		it.Equal(n, 1),
+	)
+}
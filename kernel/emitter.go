@@ -12,6 +12,7 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/fogfish/swarm"
 	"github.com/fogfish/swarm/kernel/broadcast"
@@ -79,6 +80,14 @@ func (k *EmitterIO) Await() {
 	k.Emitter.Close()
 }
 
+// StampTimeToLive stamps expiration time on the bag if time-to-live is configured
+// and the bag does not declare its own expiration time.
+func (k *EmitterIO) StampTimeToLive(bag *swarm.Bag) {
+	if k.Config.TimeToLive > 0 && bag.Expires.IsZero() {
+		bag.Expires = time.Now().Add(k.Config.TimeToLive)
+	}
+}
+
 // Creates pair of channels within kernel to emit messages to broker.
 func EmitChan[T any](k *EmitterIO, codec Encoder[T]) (chan<- T, <-chan T) {
 	snd := make(chan T, k.Config.CapOut)
@@ -105,6 +114,8 @@ func EmitChan[T any](k *EmitterIO, codec Encoder[T]) (chan<- T, <-chan T) {
 			return
 		}
 
+		k.StampTimeToLive(&bag)
+
 		err = k.Config.Backoff.Retry(func() error {
 			return k.Emitter.Enq(context.Background(), bag)
 		})
@@ -205,6 +216,8 @@ func EmitEvent[E swarm.Event[M, T], M, T any](k *EmitterIO, codec Encoder[swarm.
 			return
 		}
 
+		k.StampTimeToLive(&bag)
+
 		err = k.Config.Backoff.Retry(func() error {
 			return k.Emitter.Enq(context.Background(), bag)
 		})
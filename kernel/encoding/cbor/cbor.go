@@ -0,0 +1,83 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package cbor implements CBOR codecs for typed objects and events.
+package cbor
+
+import (
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Note: CBOR falls back to `json` tags for naming the fields.
+// The time is encoded as RFC3339 string with nanoseconds, preserving precision.
+var encMode, _ = cbor.EncOptions{
+	Time: cbor.TimeRFC3339Nano,
+}.EncMode()
+
+//------------------------------------------------------------------------------
+
+// CBOR encoding for typed objects
+type Typed[T any] string
+
+func (c Typed[T]) Category() string { return string(c) }
+
+func (c Typed[T]) Encode(obj T) (swarm.Bag, error) {
+	msg, err := encMode.Marshal(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return swarm.Bag{
+		Category: string(c),
+		Object:   msg,
+	}, nil
+}
+
+func (Typed[T]) Decode(bag swarm.Bag) (x T, err error) {
+	err = cbor.Unmarshal(bag.Object, &x)
+	return
+}
+
+// Create CBOR codec for typed objects
+func ForTyped[T any](category ...string) Typed[T] {
+	return Typed[T](swarm.TypeOf[T](category...))
+}
+
+//------------------------------------------------------------------------------
+
+// CBOR encoding for events, preserves the Meta/Data split.
+type Event[M, T any] struct{ encoding.Event[M, T] }
+
+func (c Event[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	obj = c.Stamp(obj)
+	msg, err := encMode.Marshal(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return swarm.Bag{
+		Category: c.Category(),
+		Object:   msg,
+	}, nil
+}
+
+func (c Event[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
+	var x swarm.Event[M, T]
+	err := cbor.Unmarshal(bag.Object, &x)
+
+	return x, err
+}
+
+// Creates CBOR codec for events
+func ForEvent[E swarm.Event[M, T], M, T any](realm, agent string, category ...string) Event[M, T] {
+	return Event[M, T]{
+		Event: encoding.ForEvent[E](realm, agent, category...),
+	}
+}
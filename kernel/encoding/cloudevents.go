@@ -0,0 +1,266 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+)
+
+const (
+	ErrCloudEventSpec = faults.Safe1[string]("unsupported cloudevents spec version %s")
+)
+
+// Version of CloudEvents specification
+const cloudEventsSpecVersion = "1.0"
+
+// CloudEvents requires source attribute, it is used if agent is not defined
+const cloudEventsDefaultSource = "swarm"
+
+// Mapping of metadata attributes to CloudEvents context attributes
+var (
+	cloudEventsFromMeta = map[string]string{
+		"id":      "id",
+		"type":    "type",
+		"agent":   "source",
+		"created": "time",
+		"target":  "subject",
+	}
+
+	cloudEventsToMeta = map[string]string{
+		"id":      "id",
+		"type":    "type",
+		"source":  "agent",
+		"time":    "created",
+		"subject": "target",
+	}
+)
+
+// CloudEvents 1.0 structured mode JSON encoding for events.
+//
+// The codec maps metadata attributes ID, Type, Agent, Created and Target to
+// CloudEvents context attributes id, type, source, time and subject.
+// Other attributes of metadata are mapped to CloudEvents extension attributes,
+// the name of extension is lower case alphanumeric name of the attribute.
+// Extensions are scalar values, the structured attributes (objects and arrays)
+// are serialized as JSON strings. The source defaults to "swarm" if
+// the agent is not defined.
+type CloudEvent[M, T any] struct {
+	Event[M, T]
+	extensions map[string]string
+	textual    map[string]bool
+}
+
+func (c CloudEvent[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	obj = c.Stamp(obj)
+
+	meta, err := json.Marshal(obj.Meta)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(meta, &attrs); err != nil {
+		return swarm.Bag{}, err
+	}
+
+	evt := map[string]json.RawMessage{
+		"specversion":     json.RawMessage(`"` + cloudEventsSpecVersion + `"`),
+		"datacontenttype": json.RawMessage(`"application/json"`),
+	}
+
+	for key, val := range attrs {
+		if attr, has := cloudEventsFromMeta[key]; has {
+			evt[attr] = val
+			continue
+		}
+
+		if isJsonStructured(val) {
+			val, err = json.Marshal(string(val))
+			if err != nil {
+				return swarm.Bag{}, err
+			}
+		}
+		evt[cloudEventsExtension(key)] = val
+	}
+
+	if _, has := evt["source"]; !has {
+		evt["source"] = json.RawMessage(`"` + cloudEventsDefaultSource + `"`)
+	}
+
+	if obj.Data != nil {
+		evt["data"], err = json.Marshal(obj.Data)
+		if err != nil {
+			return swarm.Bag{}, err
+		}
+	}
+
+	msg, err := json.Marshal(evt)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return swarm.Bag{
+		Category: string(c.cat),
+		Object:   msg,
+	}, nil
+}
+
+func (c CloudEvent[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal(bag.Object, &evt); err != nil {
+		return swarm.Event[M, T]{}, err
+	}
+
+	var spec string
+	if err := json.Unmarshal(evt["specversion"], &spec); err != nil || spec != cloudEventsSpecVersion {
+		return swarm.Event[M, T]{}, ErrCloudEventSpec.With(err, spec)
+	}
+
+	attrs := map[string]json.RawMessage{}
+	for attr, val := range evt {
+		switch attr {
+		case "specversion", "datacontenttype", "dataschema", "data", "data_base64":
+			continue
+		}
+
+		if key, has := cloudEventsToMeta[attr]; has {
+			attrs[key] = val
+			continue
+		}
+
+		if key, has := c.extensions[attr]; has {
+			attr = key
+		}
+
+		if !c.textual[attr] {
+			val = cloudEventsExtensionValue(val)
+		}
+		attrs[attr] = val
+	}
+
+	meta, err := json.Marshal(attrs)
+	if err != nil {
+		return swarm.Event[M, T]{}, err
+	}
+
+	obj := swarm.Event[M, T]{Meta: new(M)}
+	if err := json.Unmarshal(meta, obj.Meta); err != nil {
+		return swarm.Event[M, T]{}, err
+	}
+
+	data := []byte(evt["data"])
+	if raw, has := evt["data_base64"]; has {
+		var octets []byte
+		if err := json.Unmarshal(raw, &octets); err != nil {
+			return swarm.Event[M, T]{}, err
+		}
+		data = octets
+	}
+
+	if len(data) != 0 {
+		obj.Data = new(T)
+		if err := json.Unmarshal(data, obj.Data); err != nil {
+			return swarm.Event[M, T]{}, err
+		}
+	}
+
+	return obj, nil
+}
+
+// Creates CloudEvents 1.0 structured mode JSON codec for events
+func ForCloudEvent[E swarm.Event[M, T], M, T any](realm, agent string, category ...string) CloudEvent[M, T] {
+	extensions := map[string]string{}
+	textual := map[string]bool{}
+	for key, t := range jsonFields(reflect.TypeOf(new(M)).Elem()) {
+		extensions[cloudEventsExtension(key)] = key
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		textual[key] = t.Kind() == reflect.String
+	}
+
+	return CloudEvent[M, T]{
+		Event:      ForEvent[E](realm, agent, category...),
+		extensions: extensions,
+		textual:    textual,
+	}
+}
+
+// CloudEvents extension name consists of lower case letters and digits
+func cloudEventsExtension(key string) string {
+	return strings.Map(
+		func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+				return r
+			case r >= 'A' && r <= 'Z':
+				return r + 'a' - 'A'
+			default:
+				return -1
+			}
+		},
+		key,
+	)
+}
+
+// structured extension is serialized as JSON string, it is unwrapped back
+// if the string contains JSON object or array. String attributes of metadata
+// are never unwrapped.
+func cloudEventsExtensionValue(val json.RawMessage) json.RawMessage {
+	var str string
+	if len(val) == 0 || val[0] != '"' || json.Unmarshal(val, &str) != nil {
+		return val
+	}
+
+	if raw := json.RawMessage(str); isJsonStructured(raw) && json.Valid(raw) {
+		return raw
+	}
+
+	return val
+}
+
+func isJsonStructured(val json.RawMessage) bool {
+	return len(val) > 0 && (val[0] == '{' || val[0] == '[')
+}
+
+// JSON attributes of the struct and their types, including embedded one
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+
+		switch {
+		case tag == "-" || !f.IsExported():
+			continue
+		case f.Anonymous && tag == "":
+			for key, t := range jsonFields(f.Type) {
+				fields[key] = t
+			}
+		case tag != "":
+			fields[tag] = f.Type
+		default:
+			fields[f.Name] = f.Type
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,189 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/fogfish/curie/v2"
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+type TenantMeta struct {
+	swarm.Meta
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+type TenantEvent = swarm.Event[TenantMeta, User]
+
+type LabelMeta struct {
+	swarm.Meta
+	Labels map[string]string `json:"labels,omitempty"`
+	Note   string            `json:"note,omitempty"`
+}
+
+type LabelEvent = swarm.Event[LabelMeta, User]
+
+func TestCloudEvent(t *testing.T) {
+	codec := encoding.ForCloudEvent[UserEvent]("realm", "agent")
+
+	t.Run("Encode", func(t *testing.T) {
+		bag, err := codec.Encode(UserEvent{
+			Meta: &swarm.Meta{Target: "target"},
+			Data: &User{FirstName: "John", LastName: "Doe"},
+		})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(bag.Category, "User"),
+			it.Json(json.RawMessage(bag.Object)).Equiv(`
+				{
+					"specversion": "1.0",
+					"datacontenttype": "application/json",
+					"id": "_",
+					"type": "User",
+					"source": "agent",
+					"time": "_",
+					"subject": "target",
+					"realm": "realm",
+					"data": {"firstName": "John", "lastName": "Doe"}
+				}
+			`),
+		)
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		bag := swarm.Bag{
+			Category: "User",
+			Object: []byte(`
+				{
+					"specversion": "1.0",
+					"id": "A234-1234-1234",
+					"type": "User",
+					"source": "https://example.com/partner",
+					"time": "2025-04-05T17:31:00Z",
+					"subject": "target",
+					"datacontenttype": "application/json",
+					"data": {"firstName": "John", "lastName": "Doe"}
+				}
+			`),
+		}
+
+		evt, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.ID, "A234-1234-1234"),
+			it.Equal(evt.Meta.Type, "User"),
+			it.Equal(evt.Meta.Agent, "https://example.com/partner"),
+			it.Equal(evt.Meta.Target, "target"),
+			it.Equal(evt.Meta.Created.Unix(), 1743874260),
+			it.Equal(evt.Data.FirstName, "John"),
+		)
+	})
+
+	t.Run("Decode.Base64", func(t *testing.T) {
+		bag := swarm.Bag{
+			Object: []byte(`{"specversion": "1.0", "id": "1", "type": "User", "source": "agent", "data_base64": "eyJmaXJzdE5hbWUiOiJKb2huIn0="}`),
+		}
+
+		evt, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Data.FirstName, "John"),
+		)
+	})
+
+	t.Run("Decode.Spec", func(t *testing.T) {
+		_, err := codec.Decode(swarm.Bag{Object: []byte(`{"specversion": "0.3", "id": "1"}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrCloudEventSpec)),
+		)
+	})
+
+	t.Run("Extensions", func(t *testing.T) {
+		codec := encoding.ForCloudEvent[TenantEvent]("realm", "agent")
+
+		bag, err := codec.Encode(TenantEvent{
+			Meta: &TenantMeta{TenantID: "acme", Meta: swarm.Meta{Participant: curie.IRI("user")}},
+			Data: &User{FirstName: "John"},
+		})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Json(json.RawMessage(bag.Object)).Equiv(`
+				{
+					"specversion": "1.0",
+					"datacontenttype": "application/json",
+					"id": "_",
+					"type": "User",
+					"source": "agent",
+					"time": "_",
+					"realm": "realm",
+					"participant": "user",
+					"tenantid": "acme",
+					"data": {"firstName": "John", "lastName": ""}
+				}
+			`),
+		)
+
+		evt, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.TenantID, "acme"),
+			it.Equal(evt.Meta.Participant, "user"),
+			it.Equal(evt.Meta.Realm, "realm"),
+			it.Equal(evt.Data.FirstName, "John"),
+		)
+	})
+	t.Run("Source", func(t *testing.T) {
+		codec := encoding.ForCloudEvent[UserEvent]("realm", "")
+
+		bag, err := codec.Encode(UserEvent{Data: &User{FirstName: "John"}})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Json(json.RawMessage(bag.Object)).Equiv(`
+				{
+					"specversion": "1.0",
+					"source": "swarm",
+					"id": "_",
+					"type": "User",
+					"time": "_",
+					"realm": "realm"
+				}
+			`),
+		)
+	})
+
+	t.Run("Extensions.Structured", func(t *testing.T) {
+		codec := encoding.ForCloudEvent[LabelEvent]("realm", "agent")
+
+		bag, err := codec.Encode(LabelEvent{
+			Meta: &LabelMeta{Labels: map[string]string{"a": "b"}, Note: `{"a":"b"}`},
+			Data: &User{FirstName: "John"},
+		})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Json(json.RawMessage(bag.Object)).Equiv(`
+				{
+					"labels": "{\"a\":\"b\"}",
+					"note": "{\"a\":\"b\"}"
+				}
+			`),
+		)
+
+		evt, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.Labels["a"], "b"),
+			it.Equal(evt.Meta.Note, `{"a":"b"}`),
+		)
+	})
+}
@@ -60,6 +60,23 @@ type Event[M, T any] struct {
 func (c Event[M, T]) Category() string { return string(c.cat) }
 
 func (c Event[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	obj = c.Stamp(obj)
+	msg, err := json.Marshal(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return swarm.Bag{
+		// Note: the message category MUST BE always derived from the Type.
+		//       Metadata type NOT NOT override it.
+		Category: string(c.cat),
+		Object:   msg,
+	}, nil
+}
+
+// Stamp the event's metadata with identity, type, realm, agent and time.
+// Codecs of other formats embed Event and stamp the event before encoding it.
+func (c Event[M, T]) Stamp(obj swarm.Event[M, T]) swarm.Event[M, T] {
 	if obj.Meta == nil {
 		obj.Meta = new(M)
 	}
@@ -78,17 +95,7 @@ func (c Event[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
 	}
 
 	c.shape.Put(obj.Meta, guid.G(guid.Clock).String(), cat, rlm, agt, time.Now())
-	msg, err := json.Marshal(obj)
-	if err != nil {
-		return swarm.Bag{}, err
-	}
-
-	return swarm.Bag{
-		// Note: the message category MUST BE always derived from the Type.
-		//       Metadata type NOT NOT override it.
-		Category: string(c.cat),
-		Object:   msg,
-	}, nil
+	return obj
 }
 
 func (c Event[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
@@ -0,0 +1,168 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+)
+
+// Codec is a pair of encoder and decoder for type T.
+// It is a counterpart of kernel.Encoder and kernel.Decoder used by codec wrappers.
+type Codec[T any] interface {
+	Category() string
+	Encode(T) (swarm.Bag, error)
+	Decode(swarm.Bag) (T, error)
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	ErrCompression     = faults.Safe1[byte]("unknown compression algorithm %c")
+	ErrDecompressLimit = faults.Safe1[int]("decompressed payload exceeds limit of %d bytes")
+)
+
+// Default limit of decompressed payload size, it protects listeners from
+// decompression bombs (a small message decompressed into gigabytes).
+const DefaultDecompressLimit = 64 * 1024 * 1024
+
+// Compression algorithm. The package implements Gzip, the module
+// github.com/fogfish/swarm/kernel/encoding/zstd implements Zstd.
+type Compression interface {
+	// Marker of the algorithm in compressed payload
+	Algorithm() byte
+
+	// Appends compressed src to dst
+	Compress(dst, src []byte) ([]byte, error)
+
+	// Decompresses src, it fails with ErrDecompressLimit if the output exceeds limit (bytes)
+	Decompress(src []byte, limit int) ([]byte, error)
+}
+
+// Compressed payload is marked with magic prefix followed by algorithm.
+// The prefix is not valid UTF-8, it never collides with text payloads.
+var magicCompressed = []byte{0xff, 's', 'w', 'z'}
+
+// Gzip compression algorithm
+var Gzip Compression = gzipAlgorithm{}
+
+type gzipAlgorithm struct{}
+
+func (gzipAlgorithm) Algorithm() byte { return 'g' }
+
+func (gzipAlgorithm) Compress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipAlgorithm) Decompress(src []byte, limit int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	obj, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(obj) > limit {
+		return nil, ErrDecompressLimit.With(nil, limit)
+	}
+
+	return obj, nil
+}
+
+// Compressed codec wraps any codec, it compresses payload above the threshold.
+// Decoder transparently decompresses payloads marked with the algorithm or gzip,
+// others are passed as-is.
+type Compressed[T any] struct {
+	Codec[T]
+	algorithm Compression
+	threshold int
+	limit     int
+}
+
+func (c Compressed[T]) Encode(obj T) (swarm.Bag, error) {
+	bag, err := c.Codec.Encode(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	if len(bag.Object) < c.threshold {
+		return bag, nil
+	}
+
+	head := append(bytes.Clone(magicCompressed), c.algorithm.Algorithm())
+	bag.Object, err = c.algorithm.Compress(head, bag.Object)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return bag, nil
+}
+
+func (c Compressed[T]) Decode(bag swarm.Bag) (T, error) {
+	if len(bag.Object) <= len(magicCompressed) || !bytes.HasPrefix(bag.Object, magicCompressed) {
+		return c.Codec.Decode(bag)
+	}
+
+	algorithm := bag.Object[len(magicCompressed)]
+	payload := bag.Object[len(magicCompressed)+1:]
+
+	var decoder Compression
+	switch algorithm {
+	case c.algorithm.Algorithm():
+		decoder = c.algorithm
+	case Gzip.Algorithm():
+		decoder = Gzip
+	default:
+		return *new(T), ErrCompression.With(nil, algorithm)
+	}
+
+	obj, err := decoder.Decompress(payload, c.limit)
+	if err != nil {
+		return *new(T), err
+	}
+	bag.Object = obj
+
+	return c.Codec.Decode(bag)
+}
+
+// WithDecompressLimit configures max size of decompressed payload (bytes),
+// decoder fails larger payloads. Default is DefaultDecompressLimit.
+func (c Compressed[T]) WithDecompressLimit(limit int) Compressed[T] {
+	c.limit = limit
+	return c
+}
+
+// Compress wraps the codec with compression of payloads above threshold (bytes).
+//
+//	codec := encoding.Compress(encoding.ForTyped[User](), encoding.Gzip, 1024)
+//	snd, dlq := emit.Typed(q, codec)
+func Compress[T any](codec Codec[T], algorithm Compression, threshold int) Compressed[T] {
+	return Compressed[T]{
+		Codec:     codec,
+		algorithm: algorithm,
+		threshold: threshold,
+		limit:     DefaultDecompressLimit,
+	}
+}
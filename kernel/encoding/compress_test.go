@@ -0,0 +1,96 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+func TestCompressed(t *testing.T) {
+	user := User{FirstName: strings.Repeat("John", 100), LastName: "Doe"}
+
+	t.Run("Gzip", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForTyped[User](), encoding.Gzip, 64)
+
+		bag, err := codec.Encode(user)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(bag.Category, "User"),
+			it.Less(len(bag.Object), 100),
+		)
+
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, user.FirstName),
+			it.Equal(obj.LastName, user.LastName),
+		)
+	})
+
+	t.Run("Threshold", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForTyped[User](), encoding.Gzip, 1024)
+
+		bag, err := codec.Encode(User{FirstName: "John", LastName: "Doe"})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equiv(bag.Object, []byte(`{"firstName":"John","lastName":"Doe"}`)),
+		)
+
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, "John"),
+		)
+	})
+
+	t.Run("Event", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForEvent[UserEvent]("realm", "agent"), encoding.Gzip, 0)
+
+		bag, err := codec.Encode(UserEvent{Data: &user})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(bytes.HasPrefix(bag.Object, []byte{0xff, 's', 'w', 'z', 'g'})),
+		)
+
+		evt, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.Type, "User"),
+			it.Equal(evt.Data.FirstName, user.FirstName),
+		)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForTyped[User](), encoding.Gzip, 0)
+
+		_, err := codec.Decode(swarm.Bag{Object: []byte{0xff, 's', 'w', 'z', 'x', 0x00}})
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForTyped[User](), encoding.Gzip, 0)
+
+		bag, err := codec.Encode(User{FirstName: strings.Repeat("x", 64*1024)})
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = codec.WithDecompressLimit(1024).Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrDecompressLimit)),
+		)
+	})
+}
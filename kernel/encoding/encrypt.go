@@ -0,0 +1,391 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+)
+
+const (
+	ErrKeyProvider = faults.Type("key provider failed")
+	ErrEnvelope    = faults.Type("malformed envelope")
+	ErrDecrypt     = faults.Type("decryption failed")
+)
+
+// KeyProvider generates and decrypts data keys for envelope encryption.
+// The data key is AES-256 key, used to encrypt payloads with AES-GCM.
+type KeyProvider interface {
+	// GenerateDataKey returns plaintext data key and its encrypted form.
+	GenerateDataKey(ctx context.Context) (key []byte, sealed []byte, err error)
+
+	// DecryptDataKey returns plaintext data key from its encrypted form.
+	DecryptDataKey(ctx context.Context, sealed []byte) ([]byte, error)
+}
+
+//------------------------------------------------------------------------------
+
+// StaticKey is local key provider, it seals data keys with static master key.
+// It is suitable for testing or environment without key management services.
+type StaticKey struct{ aead cipher.AEAD }
+
+var _ KeyProvider = (*StaticKey)(nil)
+
+// Creates local key provider from master key, the key is either 16, 24 or 32 bytes.
+func NewStaticKey(key []byte) (*StaticKey, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaticKey{aead: aead}, nil
+}
+
+func (k *StaticKey) GenerateDataKey(context.Context) ([]byte, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+
+	sealed, err := seal(k.aead, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, sealed, nil
+}
+
+func (k *StaticKey) DecryptDataKey(_ context.Context, sealed []byte) ([]byte, error) {
+	return open(k.aead, sealed)
+}
+
+//------------------------------------------------------------------------------
+
+// Encrypted payload is marked with magic prefix. The envelope is
+//
+//	magic | uint16 len(sealed key) | sealed key | nonce | ciphertext
+var magicEncrypted = []byte{0xff, 's', 'w', 'e'}
+
+// Time to live of data key, the key is rotated after expiration.
+const dataKeyTTL = 5 * time.Minute
+
+// Timeout of key provider i/o, aligned with default network timeout of kernel.
+const keyProviderTimeout = 5 * time.Second
+
+// cache of data keys shared by copies of the codec
+type dataKeys struct {
+	sync.Mutex
+	provider KeyProvider
+
+	// data key used for encryption
+	key     cipher.AEAD
+	sealed  []byte
+	expires time.Time
+
+	// data keys used for decryption
+	keys map[string]cipher.AEAD
+}
+
+// Note: the lock is not held while key provider is called, concurrent
+// rotation of expired key might generate few data keys, the last one is cached.
+func (dk *dataKeys) encryptor() (cipher.AEAD, []byte, error) {
+	dk.Lock()
+	if dk.key != nil && time.Now().Before(dk.expires) {
+		defer dk.Unlock()
+		return dk.key, dk.sealed, nil
+	}
+	dk.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyProviderTimeout)
+	defer cancel()
+
+	key, sealed, err := dk.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, nil, ErrKeyProvider.With(err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, nil, ErrKeyProvider.With(err)
+	}
+
+	dk.Lock()
+	defer dk.Unlock()
+
+	dk.key, dk.sealed, dk.expires = aead, sealed, time.Now().Add(dataKeyTTL)
+	return aead, sealed, nil
+}
+
+func (dk *dataKeys) decryptor(sealed []byte) (cipher.AEAD, error) {
+	dk.Lock()
+	aead, has := dk.keys[string(sealed)]
+	dk.Unlock()
+
+	if has {
+		return aead, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyProviderTimeout)
+	defer cancel()
+
+	key, err := dk.provider.DecryptDataKey(ctx, sealed)
+	if err != nil {
+		return nil, ErrKeyProvider.With(err)
+	}
+
+	aead, err = newAEAD(key)
+	if err != nil {
+		return nil, ErrKeyProvider.With(err)
+	}
+
+	dk.Lock()
+	defer dk.Unlock()
+
+	// Note: the cache is small, it only holds keys of recently received messages
+	const maxKeys = 64
+	if len(dk.keys) >= maxKeys {
+		clear(dk.keys)
+	}
+	dk.keys[string(sealed)] = aead
+
+	return aead, nil
+}
+
+// encrypt plaintext, the additional data is authenticated but not encrypted,
+// the ciphertext cannot be decrypted in context of other additional data.
+func (dk *dataKeys) encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	aead, sealed, err := dk.encryptor()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := bytes.NewBuffer(nil)
+	msg.Write(magicEncrypted)
+	binary.Write(msg, binary.BigEndian, uint16(len(sealed)))
+	msg.Write(sealed)
+
+	ciphertext, err := sealWith(aead, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	msg.Write(ciphertext)
+
+	return msg.Bytes(), nil
+}
+
+func (dk *dataKeys) decrypt(envelope, additionalData []byte) ([]byte, error) {
+	if !bytes.HasPrefix(envelope, magicEncrypted) || len(envelope) < len(magicEncrypted)+2 {
+		return nil, ErrEnvelope
+	}
+	envelope = envelope[len(magicEncrypted):]
+
+	size := int(binary.BigEndian.Uint16(envelope))
+	envelope = envelope[2:]
+	if len(envelope) < size {
+		return nil, ErrEnvelope
+	}
+
+	aead, err := dk.decryptor(envelope[:size])
+	if err != nil {
+		return nil, err
+	}
+
+	return openWith(aead, envelope[size:], additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal plaintext, the nonce is prepended to ciphertext
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	return sealWith(aead, plaintext, nil)
+}
+
+func sealWith(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	return openWith(aead, ciphertext, nil)
+}
+
+func openWith(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrEnvelope
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt.With(err)
+	}
+
+	return plaintext, nil
+}
+
+//------------------------------------------------------------------------------
+
+// Encrypted codec wraps any codec, it encrypts the payload using envelope
+// encryption: the payload is encrypted with data key (AES-GCM), the data key
+// is encrypted by the key provider and transferred along with the payload.
+// The ciphertext is bound to the codec's category (additional authenticated data).
+type Encrypted[T any] struct {
+	Codec[T]
+	keys *dataKeys
+}
+
+func (c Encrypted[T]) Encode(obj T) (swarm.Bag, error) {
+	bag, err := c.Codec.Encode(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	bag.Object, err = c.keys.encrypt(bag.Object, []byte(c.Codec.Category()))
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return bag, nil
+}
+
+func (c Encrypted[T]) Decode(bag swarm.Bag) (T, error) {
+	var err error
+	bag.Object, err = c.keys.decrypt(bag.Object, []byte(c.Codec.Category()))
+	if err != nil {
+		return *new(T), err
+	}
+
+	return c.Codec.Decode(bag)
+}
+
+// Encrypt wraps the codec with envelope encryption of the payload.
+//
+//	keys, err := encoding.NewStaticKey(masterKey)
+//	codec := encoding.Encrypt(encoding.ForTyped[User](), keys)
+func Encrypt[T any](codec Codec[T], keys KeyProvider) Encrypted[T] {
+	return Encrypted[T]{
+		Codec: codec,
+		keys:  &dataKeys{provider: keys, keys: map[string]cipher.AEAD{}},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// EncryptedEvent codec wraps any JSON codec of events, it encrypts only
+// the event's data using envelope encryption. The metadata remains readable
+// for routing. The encrypted data is transferred as base64 JSON string.
+// The ciphertext is bound to the event's metadata (additional authenticated data).
+type EncryptedEvent[M, T any] struct {
+	Codec[swarm.Event[M, T]]
+	keys *dataKeys
+}
+
+type encryptedEvent struct {
+	Meta json.RawMessage `json:"meta,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+func (c EncryptedEvent[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	bag, err := c.Codec.Encode(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	var evt encryptedEvent
+	if err := json.Unmarshal(bag.Object, &evt); err != nil {
+		return swarm.Bag{}, err
+	}
+
+	if len(evt.Data) != 0 {
+		data, err := c.keys.encrypt(evt.Data, metaOf(evt.Meta))
+		if err != nil {
+			return swarm.Bag{}, err
+		}
+
+		evt.Data, err = json.Marshal(data)
+		if err != nil {
+			return swarm.Bag{}, err
+		}
+	}
+
+	bag.Object, err = json.Marshal(evt)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return bag, nil
+}
+
+func (c EncryptedEvent[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
+	var evt encryptedEvent
+	if err := json.Unmarshal(bag.Object, &evt); err != nil {
+		return swarm.Event[M, T]{}, err
+	}
+
+	if len(evt.Data) != 0 {
+		var data []byte
+		if err := json.Unmarshal(evt.Data, &data); err != nil {
+			return swarm.Event[M, T]{}, ErrEnvelope.With(err)
+		}
+
+		plaintext, err := c.keys.decrypt(data, metaOf(evt.Meta))
+		if err != nil {
+			return swarm.Event[M, T]{}, err
+		}
+		evt.Data = plaintext
+
+		bag.Object, err = json.Marshal(evt)
+		if err != nil {
+			return swarm.Event[M, T]{}, err
+		}
+	}
+
+	return c.Codec.Decode(bag)
+}
+
+// EncryptEvent wraps the JSON codec of events with envelope encryption of the event's data.
+//
+//	codec := encoding.EncryptEvent(encoding.ForEvent[UserEvent](realm, agent), keys)
+func EncryptEvent[M, T any](codec Codec[swarm.Event[M, T]], keys KeyProvider) EncryptedEvent[M, T] {
+	return EncryptedEvent[M, T]{
+		Codec: codec,
+		keys:  &dataKeys{provider: keys, keys: map[string]cipher.AEAD{}},
+	}
+}
+
+// canonical form of metadata used as additional authenticated data,
+// it is invariant to JSON whitespaces introduced in transit.
+func metaOf(meta json.RawMessage) []byte {
+	buf := bytes.NewBuffer(nil)
+	if err := json.Compact(buf, meta); err != nil {
+		return meta
+	}
+	return buf.Bytes()
+}
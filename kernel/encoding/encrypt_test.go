@@ -0,0 +1,182 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+var masterKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestEncrypted(t *testing.T) {
+	keys, err := encoding.NewStaticKey(masterKey)
+	it.Then(t).Should(it.Nil(err))
+
+	user := User{FirstName: "John", LastName: "Doe"}
+
+	t.Run("Typed", func(t *testing.T) {
+		codec := encoding.Encrypt(encoding.ForTyped[User](), keys)
+
+		bag, err := codec.Encode(user)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(bag.Category, "User"),
+			it.True(bytes.HasPrefix(bag.Object, []byte{0xff, 's', 'w', 'e'})),
+			it.True(!bytes.Contains(bag.Object, []byte("John"))),
+		)
+
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, user.FirstName),
+			it.Equal(obj.LastName, user.LastName),
+		)
+	})
+
+	t.Run("Event", func(t *testing.T) {
+		codec := encoding.EncryptEvent(encoding.ForEvent[UserEvent]("realm", "agent"), keys)
+
+		bag, err := codec.Encode(UserEvent{Data: &user})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(!bytes.Contains(bag.Object, []byte("John"))),
+		)
+
+		var evt struct {
+			Meta swarm.Meta `json:"meta"`
+			Data []byte     `json:"data"`
+		}
+		err = json.Unmarshal(bag.Object, &evt)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.Type, "User"),
+			it.Equal(evt.Meta.Agent, "agent"),
+			it.True(bytes.HasPrefix(evt.Data, []byte{0xff, 's', 'w', 'e'})),
+		)
+
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.Meta.Type, "User"),
+			it.Equal(obj.Data.FirstName, user.FirstName),
+		)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		other, err := encoding.NewStaticKey(bytes.Repeat([]byte{0x01}, 32))
+		it.Then(t).Should(it.Nil(err))
+
+		bag, err := encoding.Encrypt(encoding.ForTyped[User](), keys).Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = encoding.Encrypt(encoding.ForTyped[User](), other).Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrKeyProvider)),
+		)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		codec := encoding.Encrypt(encoding.ForTyped[User](), keys)
+
+		bag, err := codec.Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		bag.Object[len(bag.Object)-1] ^= 0xff
+		_, err = codec.Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrDecrypt)),
+		)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		codec := encoding.Encrypt(encoding.ForTyped[User](), keys)
+
+		_, err := codec.Decode(swarm.Bag{Object: []byte(`{"firstName":"John"}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrEnvelope)),
+		)
+	})
+
+	t.Run("Typed.OtherCategory", func(t *testing.T) {
+		bag, err := encoding.Encrypt(encoding.ForTyped[User](), keys).Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = encoding.Encrypt(encoding.ForTyped[User]("Admin"), keys).Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrDecrypt)),
+		)
+	})
+
+	t.Run("Event.OtherMeta", func(t *testing.T) {
+		codec := encoding.EncryptEvent(encoding.ForEvent[UserEvent]("realm", "agent"), keys)
+
+		a, err := codec.Encode(UserEvent{Meta: &swarm.Meta{ID: "a"}, Data: &user})
+		it.Then(t).Should(it.Nil(err))
+
+		b, err := codec.Encode(UserEvent{Meta: &swarm.Meta{ID: "b"}, Data: &User{FirstName: "Jane"}})
+		it.Then(t).Should(it.Nil(err))
+
+		// moves encrypted data of event a into event b
+		var evtA, evtB map[string]json.RawMessage
+		it.Then(t).Should(
+			it.Nil(json.Unmarshal(a.Object, &evtA)),
+			it.Nil(json.Unmarshal(b.Object, &evtB)),
+		)
+		evtB["data"] = evtA["data"]
+		b.Object, err = json.Marshal(evtB)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = codec.Decode(b)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrDecrypt)),
+		)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		provider := &deadlineKey{KeyProvider: keys}
+		codec := encoding.Encrypt(encoding.ForTyped[User](), provider)
+
+		bag, err := codec.Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = encoding.Encrypt(encoding.ForTyped[User](), provider).Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(provider.deadlines, 2),
+		)
+	})
+}
+
+// key provider counting calls with deadline
+type deadlineKey struct {
+	encoding.KeyProvider
+	deadlines int
+}
+
+func (k *deadlineKey) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	if _, has := ctx.Deadline(); has {
+		k.deadlines++
+	}
+	return k.KeyProvider.GenerateDataKey(ctx)
+}
+
+func (k *deadlineKey) DecryptDataKey(ctx context.Context, sealed []byte) ([]byte, error) {
+	if _, has := ctx.Deadline(); has {
+		k.deadlines++
+	}
+	return k.KeyProvider.DecryptDataKey(ctx, sealed)
+}
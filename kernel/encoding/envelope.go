@@ -0,0 +1,78 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding
+
+import (
+	"encoding/json"
+
+	"github.com/fogfish/swarm"
+)
+
+// JSON path to category within the envelope,
+// use it with [swarm.WithCategoryFromPayload].
+const EnvelopeCategory = "category"
+
+// Self-describing envelope of the message. JSON payload is embedded as is,
+// other payloads are embedded as base64 octets.
+type envelope struct {
+	Category string          `json:"category"`
+	Object   json.RawMessage `json:"object,omitempty"`
+	Octets   []byte          `json:"octets,omitempty"`
+}
+
+// Envelope codec wraps any codec, it embeds the category into the payload,
+// making message self-describing for brokers without attributes.
+type Envelope[T any] struct{ Codec[T] }
+
+func (c Envelope[T]) Encode(obj T) (swarm.Bag, error) {
+	bag, err := c.Codec.Encode(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	env := envelope{Category: bag.Category}
+	if json.Valid(bag.Object) {
+		env.Object = bag.Object
+	} else {
+		env.Octets = bag.Object
+	}
+
+	bag.Object, err = json.Marshal(env)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return bag, nil
+}
+
+func (c Envelope[T]) Decode(bag swarm.Bag) (T, error) {
+	var env envelope
+	if err := json.Unmarshal(bag.Object, &env); err != nil {
+		return *new(T), err
+	}
+
+	if env.Category != "" {
+		bag.Category = env.Category
+	}
+
+	bag.Object = env.Object
+	if env.Octets != nil {
+		bag.Object = env.Octets
+	}
+
+	return c.Codec.Decode(bag)
+}
+
+// Envelop wraps the codec with self-describing envelope.
+//
+//	codec := encoding.Envelop(encoding.ForTyped[User]())
+//	q := sqs.Listener().WithKernel(swarm.WithCategoryFromPayload(encoding.EnvelopeCategory))
+func Envelop[T any](codec Codec[T]) Envelope[T] {
+	return Envelope[T]{Codec: codec}
+}
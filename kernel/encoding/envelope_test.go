@@ -0,0 +1,57 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		codec := encoding.Envelop(encoding.ForTyped[User]())
+
+		bag, err := codec.Encode(User{FirstName: "John", LastName: "Doe"})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Json(json.RawMessage(bag.Object)).Equiv(`
+				{
+					"category": "User",
+					"object": {"firstName": "John", "lastName": "Doe"}
+				}
+			`),
+		)
+
+		obj, err := codec.Decode(swarm.Bag{Object: bag.Object})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, "John"),
+		)
+	})
+
+	t.Run("Octets", func(t *testing.T) {
+		codec := encoding.Envelop(encoding.ForBytes("bytes"))
+
+		bag, err := codec.Encode([]byte{0x00, 0xff})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Json(json.RawMessage(bag.Object)).Equiv(`{"category": "bytes", "octets": "AP8="}`),
+		)
+
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(obj).Equal(0x00, 0xff),
+		)
+	})
+}
@@ -0,0 +1,24 @@
+module github.com/fogfish/swarm/kernel/encoding/kms
+
+go 1.24
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.47.1
+	github.com/aws/aws-sdk-go-v2/service/kms v1.61.1
+	github.com/fogfish/it/v2 v2.2.2
+	github.com/fogfish/swarm v0.24.1
+)
+
+require (
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.5.4 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.8.4 // indirect
+	github.com/aws/smithy-go v1.28.1 // indirect
+	github.com/fogfish/curie/v2 v2.1.2 // indirect
+	github.com/fogfish/faults v0.3.2 // indirect
+	github.com/fogfish/golem/hseq v1.3.0 // indirect
+	github.com/fogfish/golem/optics v0.14.0 // indirect
+	github.com/fogfish/guid/v2 v2.1.0 // indirect
+	github.com/fogfish/opts v0.0.5 // indirect
+)
+
+replace github.com/fogfish/swarm => ../../..
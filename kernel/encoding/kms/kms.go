@@ -0,0 +1,68 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package kms implements key provider for envelope encryption using AWS KMS.
+package kms
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+// KMS declares the subset of AWS KMS api used by the key provider
+type KMS interface {
+	GenerateDataKey(context.Context, *kms.GenerateDataKeyInput, ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(context.Context, *kms.DecryptInput, ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KeyProvider generates data keys using AWS KMS key
+type KeyProvider struct {
+	service KMS
+	keyID   string
+}
+
+var _ encoding.KeyProvider = (*KeyProvider)(nil)
+
+// Creates key provider for AWS KMS key (id, alias or arn).
+//
+//	keys := kms.New(awskms.NewFromConfig(cfg), "alias/swarm")
+func New(service KMS, keyID string) *KeyProvider {
+	return &KeyProvider{service: service, keyID: keyID}
+}
+
+func (k *KeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	out, err := k.service.GenerateDataKey(ctx,
+		&kms.GenerateDataKeyInput{
+			KeyId:   aws.String(k.keyID),
+			KeySpec: types.DataKeySpecAes256,
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+func (k *KeyProvider) DecryptDataKey(ctx context.Context, sealed []byte) ([]byte, error) {
+	out, err := k.service.Decrypt(ctx,
+		&kms.DecryptInput{
+			KeyId:          aws.String(k.keyID),
+			CiphertextBlob: sealed,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Plaintext, nil
+}
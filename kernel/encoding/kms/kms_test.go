@@ -0,0 +1,62 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kms_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm/kernel/encoding"
+	"github.com/fogfish/swarm/kernel/encoding/kms"
+)
+
+type User struct {
+	Name string `json:"name"`
+}
+
+func TestKeyProvider(t *testing.T) {
+	mock := &mockKMS{}
+	codec := encoding.Encrypt(encoding.ForTyped[User](), kms.New(mock, "alias/test"))
+
+	bag, err := codec.Encode(User{Name: "John"})
+	it.Then(t).Should(it.Nil(err))
+
+	obj, err := codec.Decode(bag)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(obj.Name, "John"),
+		it.Equal(*mock.keyID, "alias/test"),
+		it.Seq(mock.sealed).Equal([]byte("sealed")...),
+	)
+}
+
+//------------------------------------------------------------------------------
+
+type mockKMS struct {
+	keyID  *string
+	sealed []byte
+}
+
+var dataKey = bytes.Repeat([]byte{0x01}, 32)
+
+func (m *mockKMS) GenerateDataKey(ctx context.Context, req *awskms.GenerateDataKeyInput, opts ...func(*awskms.Options)) (*awskms.GenerateDataKeyOutput, error) {
+	m.keyID = req.KeyId
+	return &awskms.GenerateDataKeyOutput{
+		Plaintext:      dataKey,
+		CiphertextBlob: []byte("sealed"),
+	}, nil
+}
+
+func (m *mockKMS) Decrypt(ctx context.Context, req *awskms.DecryptInput, opts ...func(*awskms.Options)) (*awskms.DecryptOutput, error) {
+	m.sealed = req.CiphertextBlob
+	return &awskms.DecryptOutput{Plaintext: dataKey}, nil
+}
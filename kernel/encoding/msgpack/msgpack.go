@@ -0,0 +1,101 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package msgpack implements MessagePack codecs for typed objects and events.
+package msgpack
+
+import (
+	"bytes"
+
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Note: the library types are annotated with `json` tags only,
+// MessagePack codec re-uses them for naming the fields.
+func marshal(obj any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetOmitEmpty(true)
+
+	if err := enc.Encode(obj); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func unmarshal(data []byte, obj any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+
+	return dec.Decode(obj)
+}
+
+//------------------------------------------------------------------------------
+
+// MessagePack encoding for typed objects
+type Typed[T any] string
+
+func (c Typed[T]) Category() string { return string(c) }
+
+func (c Typed[T]) Encode(obj T) (swarm.Bag, error) {
+	msg, err := marshal(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return swarm.Bag{
+		Category: string(c),
+		Object:   msg,
+	}, nil
+}
+
+func (Typed[T]) Decode(bag swarm.Bag) (x T, err error) {
+	err = unmarshal(bag.Object, &x)
+	return
+}
+
+// Create MessagePack codec for typed objects
+func ForTyped[T any](category ...string) Typed[T] {
+	return Typed[T](swarm.TypeOf[T](category...))
+}
+
+//------------------------------------------------------------------------------
+
+// MessagePack encoding for events, preserves the Meta/Data split.
+type Event[M, T any] struct{ encoding.Event[M, T] }
+
+func (c Event[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	obj = c.Stamp(obj)
+	msg, err := marshal(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return swarm.Bag{
+		Category: c.Category(),
+		Object:   msg,
+	}, nil
+}
+
+func (c Event[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
+	var x swarm.Event[M, T]
+	err := unmarshal(bag.Object, &x)
+
+	return x, err
+}
+
+// Creates MessagePack codec for events
+func ForEvent[E swarm.Event[M, T], M, T any](realm, agent string, category ...string) Event[M, T] {
+	return Event[M, T]{
+		Event: encoding.ForEvent[E](realm, agent, category...),
+	}
+}
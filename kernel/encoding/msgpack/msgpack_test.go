@@ -0,0 +1,63 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package msgpack_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding/msgpack"
+)
+
+type User struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type UserEvent = swarm.Event[swarm.Meta, User]
+
+func TestTyped(t *testing.T) {
+	codec := msgpack.ForTyped[User]()
+
+	bag, err := codec.Encode(User{FirstName: "John", LastName: "Doe"})
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(bag.Category, "User"),
+	)
+
+	obj, err := codec.Decode(bag)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(obj.FirstName, "John"),
+		it.Equal(obj.LastName, "Doe"),
+	)
+}
+
+func TestEvent(t *testing.T) {
+	codec := msgpack.ForEvent[UserEvent]("realm", "agent")
+
+	bag, err := codec.Encode(UserEvent{Data: &User{FirstName: "John", LastName: "Doe"}})
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(bag.Category, "User"),
+	)
+
+	evt, err := codec.Decode(bag)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(evt.Meta.Type, "User"),
+		it.Equal(evt.Meta.Realm, "realm"),
+		it.Equal(evt.Meta.Agent, "agent"),
+		it.Less(time.Since(evt.Meta.Created), time.Second),
+		it.Equal(evt.Data.FirstName, "John"),
+		it.Equal(evt.Data.LastName, "Doe"),
+	)
+}
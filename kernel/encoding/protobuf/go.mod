@@ -0,0 +1,20 @@
+module github.com/fogfish/swarm/kernel/encoding/protobuf
+
+go 1.24
+
+require (
+	github.com/fogfish/it/v2 v2.2.2
+	github.com/fogfish/swarm v0.24.1
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/fogfish/curie/v2 v2.1.2 // indirect
+	github.com/fogfish/faults v0.3.2 // indirect
+	github.com/fogfish/golem/hseq v1.3.0 // indirect
+	github.com/fogfish/golem/optics v0.14.0 // indirect
+	github.com/fogfish/guid/v2 v2.1.0 // indirect
+	github.com/fogfish/opts v0.0.5 // indirect
+)
+
+replace github.com/fogfish/swarm => ../../..
@@ -0,0 +1,148 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package protobuf implements Protobuf codecs for typed objects and events.
+package protobuf
+
+import (
+	"encoding/json"
+
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+//------------------------------------------------------------------------------
+
+// Protobuf encoding for typed objects
+type Typed[T proto.Message] string
+
+func (c Typed[T]) Category() string { return string(c) }
+
+func (c Typed[T]) Encode(obj T) (swarm.Bag, error) {
+	msg, err := proto.Marshal(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return swarm.Bag{
+		Category: string(c),
+		Object:   msg,
+	}, nil
+}
+
+func (Typed[T]) Decode(bag swarm.Bag) (T, error) {
+	x := newProto[T]()
+	err := proto.Unmarshal(bag.Object, x)
+	return x, err
+}
+
+// Create Protobuf codec for typed objects
+func ForTyped[T proto.Message](category ...string) Typed[T] {
+	return Typed[T](swarm.TypeOf[T](category...))
+}
+
+// instance of generated protobuf message type T
+func newProto[T proto.Message]() T {
+	var x T
+	return x.ProtoReflect().Type().New().Interface().(T)
+}
+
+//------------------------------------------------------------------------------
+
+// Protobuf encoding for events.
+//
+// The event is encoded as protobuf message with two fields, preserving
+// the Meta/Data split: the metadata (field 1) as JSON and the data (field 2)
+// as protobuf. Unknown fields are skipped, the envelope is forward compatible.
+//
+//	message Event {
+//	  bytes meta = 1;
+//	  bytes data = 2;
+//	}
+type Event[M any, T proto.Message] struct{ encoding.Event[M, T] }
+
+func (c Event[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	obj = c.Stamp(obj)
+
+	meta, err := json.Marshal(obj.Meta)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	var msg []byte
+	msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, meta)
+
+	if obj.Data != nil {
+		data, err := proto.Marshal(*obj.Data)
+		if err != nil {
+			return swarm.Bag{}, err
+		}
+
+		msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, data)
+	}
+
+	return swarm.Bag{
+		Category: c.Category(),
+		Object:   msg,
+	}, nil
+}
+
+func (c Event[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
+	var evt swarm.Event[M, T]
+
+	b := bag.Object
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return evt, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return evt, protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return evt, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch num {
+		case 1:
+			evt.Meta = new(M)
+			if err := json.Unmarshal(v, evt.Meta); err != nil {
+				return evt, err
+			}
+		case 2:
+			data := newProto[T]()
+			if err := proto.Unmarshal(v, data); err != nil {
+				return evt, err
+			}
+			evt.Data = &data
+		}
+	}
+
+	return evt, nil
+}
+
+// Creates Protobuf codec for events
+func ForEvent[E swarm.Event[M, T], M any, T proto.Message](realm, agent string, category ...string) Event[M, T] {
+	return Event[M, T]{
+		Event: encoding.ForEvent[E](realm, agent, category...),
+	}
+}
@@ -0,0 +1,72 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package protobuf_test
+
+import (
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding/protobuf"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestTyped(t *testing.T) {
+	codec := protobuf.ForTyped[*wrapperspb.StringValue]()
+
+	bag, err := codec.Encode(wrapperspb.String("hello"))
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(bag.Category, "StringValue"),
+	)
+
+	obj, err := codec.Decode(bag)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(obj.GetValue(), "hello"),
+	)
+}
+
+func TestEvent(t *testing.T) {
+	type E = swarm.Event[swarm.Meta, *wrapperspb.StringValue]
+
+	codec := protobuf.ForEvent[E]("realm", "agent")
+
+	data := wrapperspb.String("hello")
+	bag, err := codec.Encode(E{Data: &data})
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(bag.Category, "StringValue"),
+	)
+
+	evt, err := codec.Decode(bag)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(evt.Meta.Type, "StringValue"),
+		it.Equal(evt.Meta.Realm, "realm"),
+		it.Equal(evt.Meta.Agent, "agent"),
+		it.Equal((*evt.Data).GetValue(), "hello"),
+	)
+
+	t.Run("Unknown", func(t *testing.T) {
+		obj := bag.Object
+		obj = protowire.AppendTag(obj, 3, protowire.BytesType)
+		obj = protowire.AppendBytes(obj, []byte("unknown"))
+		obj = protowire.AppendTag(obj, 4, protowire.VarintType)
+		obj = protowire.AppendVarint(obj, 42)
+
+		evt, err := codec.Decode(swarm.Bag{Category: bag.Category, Object: obj})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.Type, "StringValue"),
+			it.Equal((*evt.Data).GetValue(), "hello"),
+		)
+	})
+}
@@ -0,0 +1,180 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package schema implements codec wrappers validating payloads against JSON Schema.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+	reflector "github.com/invopop/jsonschema"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const ErrSchema = faults.Type("invalid json schema")
+
+// JSON Schema validator
+type validator struct{ schema *jsonschema.Schema }
+
+// Creates validator from JSON Schema, the schema is generated from the type if not supplied.
+func newValidator(t reflect.Type, schema []byte) (validator, error) {
+	if schema == nil {
+		var err error
+		schema, err = json.Marshal(new(reflector.Reflector).ReflectFromType(t))
+		if err != nil {
+			return validator{}, ErrSchema.With(err)
+		}
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return validator{}, ErrSchema.With(err)
+	}
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("schema.json", doc); err != nil {
+		return validator{}, ErrSchema.With(err)
+	}
+
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		return validator{}, ErrSchema.With(err)
+	}
+
+	return validator{schema: sch}, nil
+}
+
+func (v validator) validate(cat string, obj []byte) error {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(obj))
+	if err != nil {
+		return swarm.ErrInvalid.With(err, cat)
+	}
+
+	if err := v.schema.Validate(doc); err != nil {
+		return swarm.ErrInvalid.With(err, cat)
+	}
+
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// Validated codec wraps any JSON codec, it validates the payload against
+// JSON Schema on encode and decode. Violations are reported as [swarm.ErrInvalid],
+// emitter routes the message to dead-letter channel, listener rejects the message.
+type Validated[T any] struct {
+	encoding.Codec[T]
+	validator
+}
+
+func (c Validated[T]) Encode(obj T) (swarm.Bag, error) {
+	bag, err := c.Codec.Encode(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	if err := c.validate(bag.Category, bag.Object); err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return bag, nil
+}
+
+func (c Validated[T]) Decode(bag swarm.Bag) (T, error) {
+	if err := c.validate(bag.Category, bag.Object); err != nil {
+		return *new(T), err
+	}
+
+	return c.Codec.Decode(bag)
+}
+
+// Validate wraps the JSON codec with validation of the payload against
+// JSON Schema. The schema is generated from the type T if not supplied.
+//
+//	codec, err := schema.Validate(encoding.ForTyped[User]())
+func Validate[T any](codec encoding.Codec[T], schema ...[]byte) (Validated[T], error) {
+	var sch []byte
+	if len(schema) > 0 {
+		sch = schema[0]
+	}
+
+	v, err := newValidator(reflect.TypeOf(new(T)).Elem(), sch)
+	if err != nil {
+		return Validated[T]{}, err
+	}
+
+	return Validated[T]{Codec: codec, validator: v}, nil
+}
+
+//------------------------------------------------------------------------------
+
+// ValidatedEvent codec wraps any JSON codec of events, it validates
+// only the event's data against JSON Schema on encode and decode.
+type ValidatedEvent[M, T any] struct {
+	encoding.Codec[swarm.Event[M, T]]
+	validator
+}
+
+func (c ValidatedEvent[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	bag, err := c.Codec.Encode(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	if err := c.validateData(bag); err != nil {
+		return swarm.Bag{}, err
+	}
+
+	return bag, nil
+}
+
+func (c ValidatedEvent[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
+	if err := c.validateData(bag); err != nil {
+		return swarm.Event[M, T]{}, err
+	}
+
+	return c.Codec.Decode(bag)
+}
+
+func (c ValidatedEvent[M, T]) validateData(bag swarm.Bag) error {
+	var evt struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(bag.Object, &evt); err != nil {
+		return swarm.ErrInvalid.With(err, bag.Category)
+	}
+
+	if len(evt.Data) == 0 {
+		evt.Data = json.RawMessage(`null`)
+	}
+
+	return c.validate(bag.Category, evt.Data)
+}
+
+// ValidateEvent wraps the JSON codec of events with validation of the event's
+// data against JSON Schema. The schema is generated from the type T if not supplied.
+//
+//	codec, err := schema.ValidateEvent(encoding.ForEvent[UserEvent](realm, agent))
+func ValidateEvent[M, T any](codec encoding.Codec[swarm.Event[M, T]], schema ...[]byte) (ValidatedEvent[M, T], error) {
+	var sch []byte
+	if len(schema) > 0 {
+		sch = schema[0]
+	}
+
+	v, err := newValidator(reflect.TypeOf(new(T)).Elem(), sch)
+	if err != nil {
+		return ValidatedEvent[M, T]{}, err
+	}
+
+	return ValidatedEvent[M, T]{Codec: codec, validator: v}, nil
+}
@@ -0,0 +1,92 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package schema_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+	"github.com/fogfish/swarm/kernel/encoding/schema"
+)
+
+type User struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type UserEvent = swarm.Event[swarm.Meta, User]
+
+func TestValidated(t *testing.T) {
+	t.Run("Generated", func(t *testing.T) {
+		codec, err := schema.Validate(encoding.ForTyped[User]())
+		it.Then(t).Should(it.Nil(err))
+
+		bag, err := codec.Encode(User{FirstName: "John", LastName: "Doe"})
+		it.Then(t).Should(it.Nil(err))
+
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, "John"),
+		)
+
+		_, err = codec.Decode(swarm.Bag{Category: "User", Object: []byte(`{"firstName": "John"}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrInvalid)),
+		)
+
+		_, err = codec.Decode(swarm.Bag{Category: "User", Object: []byte(`{"firstName": 1, "lastName": "Doe"}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrInvalid)),
+		)
+	})
+
+	t.Run("Supplied", func(t *testing.T) {
+		sch := []byte(`{"type": "object", "properties": {"firstName": {"type": "string", "minLength": 5}}}`)
+		codec, err := schema.Validate(encoding.ForTyped[User](), sch)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = codec.Encode(User{FirstName: "John"})
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrInvalid)),
+		)
+
+		_, err = codec.Encode(User{FirstName: "Johnny"})
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := schema.Validate(encoding.ForTyped[User](), []byte(`{"type": 1}`))
+		it.Then(t).Should(
+			it.True(errors.Is(err, schema.ErrSchema)),
+		)
+	})
+
+	t.Run("Event", func(t *testing.T) {
+		codec, err := schema.ValidateEvent(encoding.ForEvent[UserEvent]("realm", "agent"))
+		it.Then(t).Should(it.Nil(err))
+
+		bag, err := codec.Encode(UserEvent{Data: &User{FirstName: "John", LastName: "Doe"}})
+		it.Then(t).Should(it.Nil(err))
+
+		evt, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Data.FirstName, "John"),
+		)
+
+		_, err = codec.Decode(swarm.Bag{Category: "User", Object: []byte(`{"meta": {"type": "User"}, "data": {"firstName": "John"}}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrInvalid)),
+		)
+	})
+}
@@ -0,0 +1,238 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+)
+
+const (
+	ErrKeyRing     = faults.Type("key ring failed")
+	ErrKeyNotFound = faults.Safe1[string]("key %s is not found")
+	ErrKeyID       = faults.Safe1[string]("key id %s is out of range, it exceeds 255 bytes")
+	ErrVerifyOnly  = faults.Type("key only verifies signatures")
+	ErrUnsigned    = faults.Type("message is not signed")
+	ErrSignature   = faults.Safe1[string]("invalid signature (key %s)")
+)
+
+// Key signs and verifies messages
+type Key interface {
+	Sign(msg []byte) ([]byte, error)
+	Verify(msg, sig []byte) bool
+}
+
+// KeyRing is the collection of keys identified by key id.
+// The ring supports rotation, new messages are signed with active key,
+// the messages signed with previous keys are verifiable while the key is in the ring.
+type KeyRing interface {
+	// Signer returns the active key and its identity
+	Signer() (string, Key, error)
+
+	// Verifier returns the key by its identity
+	Verifier(kid string) (Key, error)
+}
+
+//------------------------------------------------------------------------------
+
+// HMAC-SHA256 key
+type HMAC []byte
+
+func (k HMAC) Sign(msg []byte) ([]byte, error) {
+	h := hmac.New(sha256.New, k)
+	h.Write(msg)
+	return h.Sum(nil), nil
+}
+
+func (k HMAC) Verify(msg, sig []byte) bool {
+	h := hmac.New(sha256.New, k)
+	h.Write(msg)
+	return hmac.Equal(h.Sum(nil), sig)
+}
+
+// Ed25519 private key, signs and verifies messages
+type Ed25519PrivateKey ed25519.PrivateKey
+
+func (k Ed25519PrivateKey) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(ed25519.PrivateKey(k), msg), nil
+}
+
+func (k Ed25519PrivateKey) Verify(msg, sig []byte) bool {
+	return ed25519.Verify(ed25519.PrivateKey(k).Public().(ed25519.PublicKey), msg, sig)
+}
+
+// Ed25519 public key, only verifies messages
+type Ed25519PublicKey ed25519.PublicKey
+
+func (k Ed25519PublicKey) Sign([]byte) ([]byte, error) {
+	return nil, ErrVerifyOnly
+}
+
+func (k Ed25519PublicKey) Verify(msg, sig []byte) bool {
+	return ed25519.Verify(ed25519.PublicKey(k), msg, sig)
+}
+
+//------------------------------------------------------------------------------
+
+// Keys is the static key ring
+type Keys struct {
+	active string
+	keys   map[string]Key
+}
+
+var _ KeyRing = (*Keys)(nil)
+
+// Creates static key ring, the active key is used for signing.
+// Consumers might leave active key empty, they only verify messages.
+// The key id is carried by the message, it is limited to 255 bytes.
+//
+//	ring, err := encoding.NewKeyRing("k2", map[string]encoding.Key{
+//		"k1": encoding.HMAC(secretV1),
+//		"k2": encoding.HMAC(secretV2),
+//	})
+func NewKeyRing(active string, keys map[string]Key) (*Keys, error) {
+	for kid := range keys {
+		if len(kid) > maxKeyID {
+			return nil, ErrKeyRing.With(ErrKeyID.With(nil, kid))
+		}
+	}
+
+	return &Keys{active: active, keys: keys}, nil
+}
+
+func (r *Keys) Signer() (string, Key, error) {
+	key, has := r.keys[r.active]
+	if !has {
+		return "", nil, ErrKeyRing.With(ErrKeyNotFound.With(nil, r.active))
+	}
+
+	return r.active, key, nil
+}
+
+func (r *Keys) Verifier(kid string) (Key, error) {
+	key, has := r.keys[kid]
+	if !has {
+		return nil, ErrKeyRing.With(ErrKeyNotFound.With(nil, kid))
+	}
+
+	return key, nil
+}
+
+//------------------------------------------------------------------------------
+
+// Signed message is marked with magic prefix. The envelope is
+//
+//	magic | uint8 len(kid) | kid | uint16 len(sig) | sig | payload
+var magicSigned = []byte{0xff, 's', 'w', 's'}
+
+// key id is prefixed by uint8 length in the envelope
+const maxKeyID = 255
+
+// Signed codec wraps any codec, it signs the encoded message (category and
+// payload) with the active key of the key ring. The decoder rejects unsigned
+// or tampered messages with [swarm.ErrInvalid].
+type Signed[T any] struct {
+	Codec[T]
+	keys KeyRing
+}
+
+func (c Signed[T]) Encode(obj T) (swarm.Bag, error) {
+	bag, err := c.Codec.Encode(obj)
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+
+	kid, key, err := c.keys.Signer()
+	if err != nil {
+		return swarm.Bag{}, err
+	}
+	if len(kid) > maxKeyID {
+		return swarm.Bag{}, ErrKeyRing.With(ErrKeyID.With(nil, kid))
+	}
+
+	sig, err := key.Sign(signedContent(bag.Category, bag.Object))
+	if err != nil {
+		return swarm.Bag{}, ErrKeyRing.With(err)
+	}
+
+	msg := bytes.NewBuffer(nil)
+	msg.Write(magicSigned)
+	msg.WriteByte(byte(len(kid)))
+	msg.WriteString(kid)
+	binary.Write(msg, binary.BigEndian, uint16(len(sig)))
+	msg.Write(sig)
+	msg.Write(bag.Object)
+
+	bag.Object = msg.Bytes()
+	return bag, nil
+}
+
+func (c Signed[T]) Decode(bag swarm.Bag) (T, error) {
+	kid, sig, obj, err := c.unpack(bag.Object)
+	if err != nil {
+		return *new(T), swarm.ErrInvalid.With(err, bag.Category)
+	}
+
+	key, err := c.keys.Verifier(kid)
+	if err != nil {
+		return *new(T), swarm.ErrInvalid.With(err, bag.Category)
+	}
+
+	if !key.Verify(signedContent(bag.Category, obj), sig) {
+		return *new(T), swarm.ErrInvalid.With(ErrSignature.With(nil, kid), bag.Category)
+	}
+
+	bag.Object = obj
+	return c.Codec.Decode(bag)
+}
+
+func (c Signed[T]) unpack(envelope []byte) (string, []byte, []byte, error) {
+	if !bytes.HasPrefix(envelope, magicSigned) || len(envelope) < len(magicSigned)+1 {
+		return "", nil, nil, ErrUnsigned
+	}
+	envelope = envelope[len(magicSigned):]
+
+	kidSize := int(envelope[0])
+	envelope = envelope[1:]
+	if len(envelope) < kidSize+2 {
+		return "", nil, nil, ErrUnsigned
+	}
+	kid := string(envelope[:kidSize])
+	envelope = envelope[kidSize:]
+
+	sigSize := int(binary.BigEndian.Uint16(envelope))
+	envelope = envelope[2:]
+	if len(envelope) < sigSize {
+		return "", nil, nil, ErrUnsigned
+	}
+
+	return kid, envelope[:sigSize], envelope[sigSize:], nil
+}
+
+// signature covers category and payload
+func signedContent(cat string, obj []byte) []byte {
+	msg := make([]byte, 0, 2+len(cat)+len(obj))
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(cat)))
+	msg = append(msg, cat...)
+	msg = append(msg, obj...)
+	return msg
+}
+
+// Sign wraps the codec with signing of messages using the key ring.
+//
+//	codec := encoding.Sign(encoding.ForEvent[UserEvent](realm, agent), ring)
+func Sign[T any](codec Codec[T], keys KeyRing) Signed[T] {
+	return Signed[T]{Codec: codec, keys: keys}
+}
@@ -0,0 +1,136 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+func TestSigned(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	it.Then(t).Should(it.Nil(err))
+
+	keys := map[string]encoding.Key{
+		"hmac":    encoding.HMAC("secret"),
+		"ed25519": encoding.Ed25519PrivateKey(priv),
+	}
+	user := User{FirstName: "John", LastName: "Doe"}
+
+	for kid := range keys {
+		t.Run(kid, func(t *testing.T) {
+			codec := encoding.Sign(encoding.ForTyped[User](), keyRing(t, kid, keys))
+
+			bag, err := codec.Encode(user)
+			it.Then(t).Should(it.Nil(err))
+
+			obj, err := codec.Decode(bag)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(obj.FirstName, user.FirstName),
+			)
+		})
+	}
+
+	t.Run("PublicKey", func(t *testing.T) {
+		bag, err := encoding.Sign(encoding.ForTyped[User](), keyRing(t, "k", map[string]encoding.Key{"k": encoding.Ed25519PrivateKey(priv)})).Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		codec := encoding.Sign(encoding.ForTyped[User](), keyRing(t, "", map[string]encoding.Key{"k": encoding.Ed25519PublicKey(pub)}))
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, user.FirstName),
+		)
+
+		_, err = codec.Encode(user)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrKeyRing)),
+		)
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		v1 := keyRing(t, "k1", map[string]encoding.Key{"k1": encoding.HMAC("v1")})
+		v2 := keyRing(t, "k2", map[string]encoding.Key{"k1": encoding.HMAC("v1"), "k2": encoding.HMAC("v2")})
+
+		bag, err := encoding.Sign(encoding.ForTyped[User](), v1).Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = encoding.Sign(encoding.ForTyped[User](), v2).Decode(bag)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		codec := encoding.Sign(encoding.ForTyped[User](), keyRing(t, "hmac", keys))
+
+		bag, err := codec.Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		bag.Object[len(bag.Object)-2] = 'X'
+		_, err = codec.Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrInvalid)),
+			it.True(errors.Is(err, encoding.ErrSignature)),
+		)
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		codec := encoding.Sign(encoding.ForTyped[User](), keyRing(t, "hmac", keys))
+
+		bag, err := codec.Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		bag.Category = "Admin"
+		_, err = codec.Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrSignature)),
+		)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		codec := encoding.Sign(encoding.ForTyped[User](), keyRing(t, "hmac", keys))
+
+		_, err := codec.Decode(swarm.Bag{Category: "User", Object: []byte(`{"firstName":"John"}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrInvalid)),
+			it.True(errors.Is(err, encoding.ErrUnsigned)),
+		)
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		bag, err := encoding.Sign(encoding.ForTyped[User](), keyRing(t, "hmac", keys)).Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = encoding.Sign(encoding.ForTyped[User](), keyRing(t, "", nil)).Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrKeyNotFound)),
+		)
+	})
+	t.Run("KeyID", func(t *testing.T) {
+		_, err := encoding.NewKeyRing("k", map[string]encoding.Key{strings.Repeat("k", 256): encoding.HMAC("secret")})
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrKeyID)),
+		)
+	})
+}
+
+func keyRing(t *testing.T, active string, keys map[string]encoding.Key) *encoding.Keys {
+	t.Helper()
+
+	ring, err := encoding.NewKeyRing(active, keys)
+	it.Then(t).Should(it.Nil(err))
+
+	return ring
+}
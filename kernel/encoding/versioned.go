@@ -0,0 +1,121 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding
+
+import (
+	"encoding/json"
+	"maps"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/golem/optics"
+	"github.com/fogfish/swarm"
+)
+
+const (
+	ErrVersionUnsupported = faults.Safe1[int]("unsupported schema version %d")
+	ErrVersionUpcast      = faults.Safe1[int]("upcast has failed (version %d)")
+	ErrVersionUndefined   = faults.Type("metadata does not define Version int attribute")
+)
+
+// Upcaster transforms raw JSON of event's data from one schema version to the next one.
+type Upcaster func(json.RawMessage) (json.RawMessage, error)
+
+// Versioned JSON encoding for events.
+//
+// Producer stamps the schema version to the event's metadata. Consumer applies
+// upcasters sequentially (v1 → v2 → ... → vN) to raw JSON of the event's data
+// before decoding it into the type T. Events without version are treated as v1.
+//
+// The metadata type M shall define `Version int` attribute (e.g. swarm.Meta),
+// the codec fails to encode and decode events otherwise.
+type VersionedEvent[M, T any] struct {
+	Event[M, T]
+	version   int
+	schema    optics.Lens[M, int] // nil if M does not define the attribute
+	upcasters map[int]Upcaster
+}
+
+func (c VersionedEvent[M, T]) Encode(obj swarm.Event[M, T]) (swarm.Bag, error) {
+	if c.schema == nil {
+		return swarm.Bag{}, ErrVersionUndefined
+	}
+
+	if obj.Meta == nil {
+		obj.Meta = new(M)
+	}
+
+	c.schema.Put(obj.Meta, c.version)
+	return c.Event.Encode(obj)
+}
+
+func (c VersionedEvent[M, T]) Decode(bag swarm.Bag) (swarm.Event[M, T], error) {
+	if c.schema == nil {
+		return swarm.Event[M, T]{}, ErrVersionUndefined
+	}
+
+	var raw struct {
+		Meta *M              `json:"meta,omitempty"`
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+
+	if err := json.Unmarshal(bag.Object, &raw); err != nil {
+		return swarm.Event[M, T]{}, err
+	}
+
+	if raw.Meta == nil {
+		raw.Meta = new(M)
+	}
+
+	version := max(c.schema.Get(raw.Meta), 1)
+	if version > c.version {
+		return swarm.Event[M, T]{}, ErrVersionUnsupported.With(nil, version)
+	}
+
+	data := raw.Data
+	for v := version; v < c.version && len(data) != 0; v++ {
+		upcast, has := c.upcasters[v]
+		if !has {
+			return swarm.Event[M, T]{}, ErrVersionUnsupported.With(nil, v)
+		}
+
+		out, err := upcast(data)
+		if err != nil {
+			return swarm.Event[M, T]{}, ErrVersionUpcast.With(err, v)
+		}
+		data = out
+	}
+	c.schema.Put(raw.Meta, c.version)
+
+	evt := swarm.Event[M, T]{Meta: raw.Meta}
+	if len(data) != 0 {
+		evt.Data = new(T)
+		if err := json.Unmarshal(data, evt.Data); err != nil {
+			return swarm.Event[M, T]{}, err
+		}
+	}
+
+	return evt, nil
+}
+
+// Upcast registers upcaster from the schema version to the next one.
+func (c VersionedEvent[M, T]) Upcast(from int, f Upcaster) VersionedEvent[M, T] {
+	c.upcasters = maps.Clone(c.upcasters)
+	c.upcasters[from] = f
+	return c
+}
+
+// Creates versioned JSON codec for events, the version is the actual schema version of type T.
+func ForVersionedEvent[E swarm.Event[M, T], M, T any](version int, realm, agent string, category ...string) VersionedEvent[M, T] {
+	return VersionedEvent[M, T]{
+		Event:     ForEvent[E](realm, agent, category...),
+		version:   version,
+		schema:    swarm.LensMaybe[M, int]("Version"),
+		upcasters: map[int]Upcaster{},
+	}
+}
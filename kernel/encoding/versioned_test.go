@@ -0,0 +1,128 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package encoding_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fogfish/curie/v2"
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+type User struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type UserEvent = swarm.Event[swarm.Meta, User]
+
+// v1 {"name": "John Doe"} → v2 {"firstName": "John", "lastName": "Doe"}
+func upcastV1(data json.RawMessage) (json.RawMessage, error) {
+	var v1 struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, err
+	}
+
+	first, last, _ := strings.Cut(v1.Name, " ")
+	return json.Marshal(map[string]string{"firstName": first, "lastName": last})
+}
+
+func TestVersionedEvent(t *testing.T) {
+	codec := encoding.ForVersionedEvent[UserEvent](2, "realm", "agent").
+		Upcast(1, upcastV1)
+
+	t.Run("Encode", func(t *testing.T) {
+		bag, err := codec.Encode(UserEvent{Data: &User{FirstName: "John", LastName: "Doe"}})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(bag.Category, "User"),
+			it.Json(json.RawMessage(bag.Object)).Equiv(`
+				{
+					"meta": {"type": "User", "version": 2, "realm": "realm", "agent": "agent", "id": "_", "created": "_"},
+					"data": {"firstName": "John", "lastName": "Doe"}
+				}
+			`),
+		)
+	})
+
+	t.Run("Decode.Actual", func(t *testing.T) {
+		evt, err := codec.Decode(swarm.Bag{Object: []byte(`{"meta": {"version": 2}, "data": {"firstName": "John", "lastName": "Doe"}}`)})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.Version, 2),
+			it.Equal(evt.Data.FirstName, "John"),
+			it.Equal(evt.Data.LastName, "Doe"),
+		)
+	})
+
+	t.Run("Decode.Upcast", func(t *testing.T) {
+		evt, err := codec.Decode(swarm.Bag{Object: []byte(`{"meta": {"version": 1}, "data": {"name": "John Doe"}}`)})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.Version, 2),
+			it.Equal(evt.Data.FirstName, "John"),
+			it.Equal(evt.Data.LastName, "Doe"),
+		)
+	})
+
+	t.Run("Decode.Unversioned", func(t *testing.T) {
+		evt, err := codec.Decode(swarm.Bag{Object: []byte(`{"data": {"name": "John Doe"}}`)})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Data.FirstName, "John"),
+		)
+	})
+
+	t.Run("Decode.Unsupported", func(t *testing.T) {
+		_, err := codec.Decode(swarm.Bag{Object: []byte(`{"meta": {"version": 3}, "data": {}}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrVersionUnsupported)),
+		)
+	})
+
+	t.Run("Decode.NoUpcaster", func(t *testing.T) {
+		codec := encoding.ForVersionedEvent[UserEvent](3, "realm", "agent").
+			Upcast(1, upcastV1)
+
+		_, err := codec.Decode(swarm.Bag{Object: []byte(`{"meta": {"version": 1}, "data": {"name": "John Doe"}}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrVersionUnsupported)),
+		)
+	})
+
+	t.Run("Meta.Unversioned", func(t *testing.T) {
+		type Meta struct {
+			ID      string    `json:"id,omitempty"`
+			Type    curie.IRI `json:"type,omitempty"`
+			Realm   curie.IRI `json:"realm,omitempty"`
+			Agent   curie.IRI `json:"agent,omitempty"`
+			Created time.Time `json:"created,omitempty"`
+		}
+
+		codec := encoding.ForVersionedEvent[swarm.Event[Meta, User]](2, "realm", "agent")
+
+		_, err := codec.Encode(swarm.Event[Meta, User]{Data: &User{FirstName: "John"}})
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrVersionUndefined)),
+		)
+
+		_, err = codec.Decode(swarm.Bag{Object: []byte(`{"data": {"firstName": "John"}}`)})
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrVersionUndefined)),
+		)
+	})
+}
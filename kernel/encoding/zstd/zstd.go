@@ -0,0 +1,67 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package zstd implements zstd compression algorithm for encoding.Compress.
+package zstd
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/fogfish/swarm/kernel/encoding"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Zstd compression algorithm
+//
+//	codec := encoding.Compress(encoding.ForTyped[User](), zstd.Zstd, 1024)
+var Zstd encoding.Compression = algorithm{}
+
+var (
+	encoder = sync.OnceValue(func() *zstd.Encoder {
+		enc, _ := zstd.NewWriter(nil)
+		return enc
+	})
+
+	// decoders are shared among codecs with same limit of decompressed size
+	decoders sync.Map
+)
+
+func decoder(limit int) *zstd.Decoder {
+	if dec, has := decoders.Load(limit); has {
+		return dec.(*zstd.Decoder)
+	}
+
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(limit)))
+	if val, loaded := decoders.LoadOrStore(limit, dec); loaded {
+		dec.Close()
+		return val.(*zstd.Decoder)
+	}
+
+	return dec
+}
+
+type algorithm struct{}
+
+func (algorithm) Algorithm() byte { return 'z' }
+
+func (algorithm) Compress(dst, src []byte) ([]byte, error) {
+	return encoder().EncodeAll(src, dst), nil
+}
+
+func (algorithm) Decompress(src []byte, limit int) ([]byte, error) {
+	obj, err := decoder(limit).DecodeAll(src, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+		return nil, encoding.ErrDecompressLimit.With(err, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
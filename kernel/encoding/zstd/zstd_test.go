@@ -0,0 +1,90 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package zstd_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+	"github.com/fogfish/swarm/kernel/encoding/zstd"
+)
+
+type User struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type UserEvent = swarm.Event[swarm.Meta, User]
+
+func TestZstd(t *testing.T) {
+	user := User{FirstName: strings.Repeat("John", 100), LastName: "Doe"}
+
+	t.Run("Typed", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForTyped[User](), zstd.Zstd, 64)
+
+		bag, err := codec.Encode(user)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(bag.Category, "User"),
+			it.Less(len(bag.Object), 100),
+		)
+
+		obj, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, user.FirstName),
+			it.Equal(obj.LastName, user.LastName),
+		)
+	})
+
+	t.Run("Event", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForEvent[UserEvent]("realm", "agent"), zstd.Zstd, 0)
+
+		bag, err := codec.Encode(UserEvent{Data: &user})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(bytes.HasPrefix(bag.Object, []byte{0xff, 's', 'w', 'z', 'z'})),
+		)
+
+		evt, err := codec.Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(evt.Meta.Type, "User"),
+			it.Equal(evt.Data.FirstName, user.FirstName),
+		)
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		bag, err := encoding.Compress(encoding.ForTyped[User](), encoding.Gzip, 0).Encode(user)
+		it.Then(t).Should(it.Nil(err))
+
+		obj, err := encoding.Compress(encoding.ForTyped[User](), zstd.Zstd, 0).Decode(bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(obj.FirstName, user.FirstName),
+		)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		codec := encoding.Compress(encoding.ForTyped[User](), zstd.Zstd, 0)
+
+		bag, err := codec.Encode(User{FirstName: strings.Repeat("x", 64*1024)})
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = codec.WithDecompressLimit(1024).Decode(bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, encoding.ErrDecompressLimit)),
+		)
+	})
+}
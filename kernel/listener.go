@@ -10,7 +10,10 @@ package kernel
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +28,31 @@ type Listener interface {
 	Close() error
 }
 
+// Spawner is the listener that runs its own loop of receiving messages
+// (e.g. serverless listeners), the kernel spawns it when awaited.
+type Spawner interface {
+	Run(ctx context.Context)
+}
+
+// Decorate the listener with the middleware that wraps it. The decorated
+// listener keeps Run of the spawner so that the kernel still spawns it.
+//
+//	q.Listener = kernel.Decorate(q.Listener, &middleware{Listener: q.Listener})
+func Decorate(listener, middleware Listener) Listener {
+	if spawner, ok := listener.(Spawner); ok {
+		return spawned{Listener: middleware, spawner: spawner}
+	}
+
+	return middleware
+}
+
+type spawned struct {
+	Listener
+	spawner Spawner
+}
+
+func (l spawned) Run(ctx context.Context) { l.spawner.Run(ctx) }
+
 // Decode message from wire format
 type Decoder[T any] interface {
 	Category() string
@@ -53,6 +81,9 @@ type ListenerIO struct {
 
 	// Listener is the reader port on message broker
 	Listener Listener
+
+	// path to category within the message payload
+	categoryPath []string
 }
 
 func NewListener(listener Listener, config swarm.Config) *ListenerIO {
@@ -68,12 +99,18 @@ func newListener(listener Listener, config swarm.Config) *ListenerIO {
 		config.PollerPool = 1
 	}
 
+	var categoryPath []string
+	if config.CategoryPath != "" {
+		categoryPath = strings.Split(config.CategoryPath, ".")
+	}
+
 	return &ListenerIO{
-		Config:   config,
-		context:  ctx,
-		cancel:   can,
-		router:   make(map[string]Router),
-		Listener: listener,
+		Config:       config,
+		context:      ctx,
+		cancel:       can,
+		router:       make(map[string]Router),
+		Listener:     listener,
+		categoryPath: categoryPath,
 	}
 }
 
@@ -84,9 +121,14 @@ func (k *ListenerIO) Close() {
 	k.Listener.Close()
 }
 
+// Context of the kernel, it is cancelled when the kernel is closed.
+// Routines that consume kernel channels should stop on its cancellation.
+// They shall join the kernel's WaitGroup so that Close awaits them.
+func (k *ListenerIO) Context() context.Context { return k.context }
+
 // Await reader to complete
 func (k *ListenerIO) Await() {
-	if spawner, ok := k.Listener.(interface{ Run(context.Context) }); ok {
+	if spawner, ok := k.Listener.(Spawner); ok {
 		go spawner.Run(k.context)
 	}
 
@@ -116,12 +158,41 @@ func (k *ListenerIO) receive() {
 		for i := 0; i < len(seq); i++ {
 			bag := seq[i]
 
+			// Note: broker has failed to transfer the message (e.g. malformed
+			// encoding), the raw content is not decodable.
+			if bag.Error != nil {
+				k.reject(bag, bag.Error)
+				continue
+			}
+
+			if k.categoryPath != nil {
+				if cat, has := categoryOf(bag.Object, k.categoryPath); has {
+					bag.Category = cat
+				}
+			}
+
 			k.RWMutex.RLock()
 			r, has := k.router[bag.Category]
 			k.RWMutex.RUnlock()
 
 			if has {
 				err := r.Route(k.context, bag)
+				if errors.Is(err, swarm.ErrIsolated) {
+					slog.Debug("Isolated message",
+						slog.Any("cat", bag.Category),
+						slog.Any("kernel", k.Config.Agent),
+					)
+					k.skip(bag)
+					continue
+				}
+				if errors.Is(err, swarm.ErrExpired) {
+					k.expire(bag)
+					continue
+				}
+				if errors.Is(err, swarm.ErrInvalid) {
+					k.reject(bag, err)
+					continue
+				}
 				if k.Config.StdErr != nil && err != nil {
 					k.Config.StdErr <- swarm.ErrDequeue.With(err)
 					return
@@ -170,6 +241,74 @@ func (k *ListenerIO) receive() {
 	}
 }
 
+// reads category from the JSON payload
+func categoryOf(obj []byte, path []string) (string, bool) {
+	raw := json.RawMessage(obj)
+	for _, key := range path {
+		var node map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &node); err != nil {
+			return "", false
+		}
+
+		val, has := node[key]
+		if !has {
+			return "", false
+		}
+		raw = val
+	}
+
+	var cat string
+	if err := json.Unmarshal(raw, &cat); err != nil || cat == "" {
+		return "", false
+	}
+
+	return cat, true
+}
+
+// acknowledges expired message without delivering it to the application
+func (k *ListenerIO) expire(bag swarm.Bag) {
+	slog.Debug("Expired message",
+		slog.Any("cat", bag.Category),
+		slog.Any("kernel", k.Config.Agent),
+	)
+
+	if k.Config.ExpiredHandler != nil {
+		k.Config.ExpiredHandler(bag)
+	}
+
+	k.skip(bag)
+}
+
+// rejects malformed message without delivering it to the application,
+// the broker handles it as poison message (e.g. moves to dead-letter queue).
+func (k *ListenerIO) reject(bag swarm.Bag, reason error) {
+	slog.Debug("Malformed message",
+		slog.Any("cat", bag.Category),
+		slog.Any("kernel", k.Config.Agent),
+		slog.Any("err", reason),
+	)
+
+	err := k.Listener.Err(k.context, bag.Digest, swarm.ErrDequeue.With(reason))
+	if k.Config.StdErr != nil {
+		if err != nil {
+			reason = err
+		}
+		k.Config.StdErr <- swarm.ErrDequeue.With(reason)
+	}
+}
+
+// acknowledges message without delivering it to the application
+func (k *ListenerIO) skip(bag swarm.Bag) {
+	err := k.Config.Backoff.Retry(
+		func() error {
+			return k.Listener.Ack(k.context, bag.Digest)
+		},
+	)
+	if k.Config.StdErr != nil && err != nil {
+		k.Config.StdErr <- swarm.ErrDequeue.With(err)
+	}
+}
+
 // RecvChan creates pair of channels within kernel to receive messages
 func RecvChan[T any](k *ListenerIO, codec Decoder[T]) (<-chan swarm.Msg[T], chan<- swarm.Msg[T]) {
 	rcv := make(chan swarm.Msg[T], k.Config.CapRcv)
@@ -179,6 +318,13 @@ func RecvChan[T any](k *ListenerIO, codec Decoder[T]) (<-chan swarm.Msg[T], chan
 	k.router[codec.Category()] = newMsgRouter[T](rcv, codec)
 	k.RWMutex.Unlock()
 
+	recvAck(k, codec.Category(), ack)
+
+	return rcv, ack
+}
+
+// spawns acknowledgement routine for the channel
+func recvAck[T any](k *ListenerIO, cat string, ack chan swarm.Msg[T]) {
 	// emitter routine
 	acks := func(msg swarm.Msg[T]) {
 		if msg.Error == nil {
@@ -204,8 +350,8 @@ func RecvChan[T any](k *ListenerIO, codec Decoder[T]) (<-chan swarm.Msg[T], chan
 
 	k.WaitGroup.Add(1)
 	go func() {
-		slog.Debug("kernel dequeue started", "cat", codec.Category())
-		defer slog.Debug("kernel dequeue stopped", "cat", codec.Category())
+		slog.Debug("kernel dequeue started", "cat", cat)
+		defer slog.Debug("kernel dequeue stopped", "cat", cat)
 
 	exit:
 		for {
@@ -240,8 +386,6 @@ func RecvChan[T any](k *ListenerIO, codec Decoder[T]) (<-chan swarm.Msg[T], chan
 
 		k.WaitGroup.Done()
 	}()
-
-	return rcv, ack
 }
 
 // RecvEvent creates pair of channels within kernel to receive events
@@ -250,7 +394,7 @@ func RecvEvent[E swarm.Event[M, T], M, T any](k *ListenerIO, codec Decoder[swarm
 	ack := make(chan swarm.Event[M, T], k.Config.CapAck)
 
 	k.RWMutex.Lock()
-	k.router[codec.Category()] = newEvtRouter(rcv, codec)
+	k.router[codec.Category()] = newEvtRouter(rcv, codec, k.Config)
 	k.RWMutex.Unlock()
 
 	// shape := optics.ForShape2[E, swarm.Digest, error]()
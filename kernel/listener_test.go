@@ -9,6 +9,7 @@
 package kernel
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -72,6 +73,220 @@ func TestRecvEvent(t *testing.T) {
 	)
 }
 
+func TestRecvEventExpired(t *testing.T) {
+	type E = swarm.Event[swarm.Meta, string]
+
+	expired := make(chan swarm.Bag, 1)
+	conf := newConfig()
+	conf.kernel.MaxEventAge = time.Minute
+	conf.kernel.ExpiredHandler = func(bag swarm.Bag) { expired <- bag }
+
+	mock := mockFactory{}
+	pass := mock.ListenerCore(make(chan string),
+		[]swarm.Bag{
+			{
+				Category: "string",
+				Digest:   "1",
+				Object:   []byte(`{"meta":{"created": "2020-01-01T00:00:00Z"}, "data": "1"}`),
+			},
+		},
+	)
+
+	k := NewListener(pass, conf.kernel)
+	RecvEvent(k, encoding.ForEvent[E]("testReal", "testAgent"))
+	go k.Await()
+
+	it.Then(t).Should(
+		it.Equal(string(<-pass.ack), `1`),
+		it.Equal((<-expired).Digest, "1"),
+	)
+
+	k.Close()
+}
+
+// codec rejects every message as invalid
+type invalid[T any] struct{ encoding.Typed[T] }
+
+func (invalid[T]) Decode(bag swarm.Bag) (T, error) {
+	return *new(T), swarm.ErrInvalid.With(nil, bag.Category)
+}
+
+func TestRecvInvalid(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	stderr := make(chan error, 1)
+	conf := newConfig()
+	conf.kernel.StdErr = stderr
+
+	mock := mockFactory{}
+	pass := mock.ListenerCore(make(chan string),
+		[]swarm.Bag{
+			{
+				Category: "User",
+				Digest:   "1",
+				Object:   []byte(`{"name": 1}`),
+			},
+		},
+	)
+
+	k := NewListener(pass, conf.kernel)
+	RecvChan(k, invalid[User]{encoding.ForTyped[User]()})
+	go k.Await()
+
+	it.Then(t).Should(
+		it.Equal(string(<-pass.ack), `1`),
+		it.True(errors.Is(<-stderr, swarm.ErrInvalid)),
+	)
+
+	k.Close()
+}
+
+func TestRecvMalformed(t *testing.T) {
+	stderr := make(chan error, 1)
+	conf := newConfig()
+	conf.kernel.StdErr = stderr
+
+	mock := mockFactory{}
+	pass := mock.ListenerCore(make(chan string),
+		[]swarm.Bag{
+			{
+				Category: "string",
+				Digest:   "1",
+				Error:    errors.New("illegal base64 data"),
+				Object:   []byte(`"1"`),
+			},
+		},
+	)
+
+	k := NewListener(pass, conf.kernel)
+	rcv, _ := RecvChan(k, encoding.ForTyped[string]())
+	go k.Await()
+
+	it.Then(t).Should(
+		it.Equal(string(<-pass.ack), `1`),
+		it.True(errors.Is(<-stderr, swarm.ErrDequeue)),
+		it.Equal(len(rcv), 0),
+	)
+
+	k.Close()
+}
+
+func TestRecvCategoryFromPayload(t *testing.T) {
+	type E = swarm.Event[swarm.Meta, string]
+
+	conf := newConfig()
+	conf.kernel.CategoryPath = "meta.type"
+
+	mock := mockFactory{}
+	pass := mock.ListenerCore(make(chan string),
+		[]swarm.Bag{
+			{
+				Digest: "1",
+				Object: []byte(`{"meta":{"type": "string"}, "data": "1"}`),
+			},
+		},
+	)
+
+	k := NewListener(pass, conf.kernel)
+	rcv, ack := RecvEvent(k, encoding.ForEvent[E]("testReal", "testAgent"))
+	go k.Await()
+
+	evt := <-rcv
+	ack <- evt
+
+	it.Then(t).Should(
+		it.Equal(*evt.Data, "1"),
+		it.Equal(string(<-pass.ack), `1`),
+	)
+
+	k.Close()
+}
+
+func TestRecvUnion(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+	type E = swarm.Event[swarm.Meta, string]
+
+	conf := newConfig()
+
+	mock := mockFactory{}
+	pass := mock.ListenerCore(make(chan string),
+		[]swarm.Bag{
+			{Category: "User", Digest: "1", Object: []byte(`{"name": "user"}`)},
+			{Category: "string", Digest: "2", Object: []byte(`{"meta":{"type": "string"}, "data": "event"}`)},
+			{Category: "User", Digest: "3", Object: []byte(`{"name": "next"}`)},
+		},
+	)
+
+	k := NewListener(pass, conf.kernel)
+	rcv, ack := RecvUnion(k,
+		CaseOf(encoding.ForTyped[User]()),
+		CaseOfEvent[E](encoding.ForEvent[E]("testReal", "testAgent")),
+	)
+	go k.Await()
+
+	a, b, c := <-rcv, <-rcv, <-rcv
+
+	user, isUser := a.Object.(User)
+	evt, isEvent := b.Object.(E)
+	next, isNext := c.Object.(User)
+
+	it.Then(t).Should(
+		it.True(isUser),
+		it.Equal(user.Name, "user"),
+		it.True(isEvent),
+		it.Equal(*evt.Data, "event"),
+		it.True(isNext),
+		it.Equal(next.Name, "next"),
+	)
+
+	for _, msg := range []swarm.Msg[any]{a, b, c} {
+		ack <- msg
+		it.Then(t).Should(
+			it.Equal(string(<-pass.ack), string(msg.Digest)),
+		)
+	}
+
+	k.Close()
+}
+
+func TestDecorate(t *testing.T) {
+	type middleware struct{ Listener }
+
+	t.Run("Spawner", func(t *testing.T) {
+		bridge := newMockBridge(newConfig(), nil)
+
+		_, ok := Decorate(bridge, middleware{bridge}).(Spawner)
+		it.Then(t).Should(it.True(ok))
+	})
+
+	t.Run("NotSpawner", func(t *testing.T) {
+		listener := newMockCathode(make(chan string), nil)
+
+		_, ok := Decorate(listener, middleware{listener}).(Spawner)
+		it.Then(t).ShouldNot(it.True(ok))
+	})
+}
+
+func TestCategoryOf(t *testing.T) {
+	for obj, expect := range map[string]string{
+		`{"meta":{"type": "User"}}`: "User",
+		`{"meta":{"type": 1}}`:      "",
+		`{"meta":{}}`:               "",
+		`{"meta":"User"}`:           "",
+		`binary`:                    "",
+	} {
+		cat, has := categoryOf([]byte(obj), []string{"meta", "type"})
+		it.Then(t).Should(
+			it.Equal(cat, expect),
+			it.Equal(has, expect != ""),
+		)
+	}
+}
+
 func recvTest[M any, T any](
 	t *testing.T,
 	codec Decoder[T],
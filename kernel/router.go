@@ -11,7 +11,10 @@ package kernel
 import (
 	"context"
 	"log/slog"
+	"time"
 
+	"github.com/fogfish/curie/v2"
+	"github.com/fogfish/golem/optics"
 	"github.com/fogfish/swarm"
 )
 
@@ -32,14 +35,9 @@ func newMsgRouter[T any](
 }
 
 func (a msgRouter[T]) Route(ctx context.Context, bag swarm.Bag) error {
-	obj, err := a.codec.Decode(bag)
+	obj, err := a.decode(bag)
 	if err != nil {
-		slog.Debug("rouetr failed to decode message",
-			slog.Any("cat", bag.Category),
-			slog.Any("bag", bag),
-			slog.Any("err", err),
-		)
-		return swarm.ErrDecoder.With(err)
+		return err
 	}
 
 	msg := swarm.ToMsg(bag, obj)
@@ -52,23 +50,78 @@ func (a msgRouter[T]) Route(ctx context.Context, bag swarm.Bag) error {
 	}
 }
 
+func (a msgRouter[T]) decode(bag swarm.Bag) (T, error) {
+	obj, err := a.codec.Decode(bag)
+	if err != nil {
+		slog.Debug("rouetr failed to decode message",
+			slog.Any("cat", bag.Category),
+			slog.Any("bag", bag),
+			slog.Any("err", err),
+		)
+		return obj, swarm.ErrDecoder.With(err)
+	}
+
+	return obj, nil
+}
+
 // Router is typed pair of message channel and codec
 type evtRouter[M, T any] struct {
 	ch    chan swarm.Event[M, T]
 	codec Decoder[swarm.Event[M, T]]
+
+	// max age of the event and lens to its creation time, nil if metadata
+	// does not define the attribute.
+	maxAge  time.Duration
+	created optics.Lens[M, time.Time]
+
+	// realms and target accepted by the router, lenses are nil if metadata
+	// does not define the attribute.
+	realms map[curie.IRI]struct{}
+	agent  curie.IRI
+	realm  optics.Lens[M, curie.IRI]
+	target optics.Lens[M, curie.IRI]
 }
 
 func newEvtRouter[E swarm.Event[M, T], M, T any](
 	ch chan swarm.Event[M, T],
 	codec Decoder[swarm.Event[M, T]],
+	config swarm.Config,
 ) evtRouter[M, T] {
+	var realms map[curie.IRI]struct{}
+	if config.Realm != "" || len(config.AcceptRealms) > 0 {
+		realms = map[curie.IRI]struct{}{curie.IRI(config.Realm): {}}
+		for _, realm := range config.AcceptRealms {
+			realms[curie.IRI(realm)] = struct{}{}
+		}
+	}
+
 	return evtRouter[M, T]{
-		ch:    ch,
-		codec: codec,
+		ch:      ch,
+		codec:   codec,
+		maxAge:  config.MaxEventAge,
+		created: swarm.LensMaybe[M, time.Time]("Created"),
+		realms:  realms,
+		agent:   curie.IRI(config.Agent),
+		realm:   swarm.LensMaybe[M, curie.IRI]("Realm"),
+		target:  swarm.LensMaybe[M, curie.IRI]("Target"),
 	}
 }
 
 func (a evtRouter[M, T]) Route(ctx context.Context, bag swarm.Bag) error {
+	evt, err := a.decode(bag)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return swarm.ErrRouting.With(nil, bag.Category)
+	case a.ch <- evt:
+		return nil
+	}
+}
+
+func (a evtRouter[M, T]) decode(bag swarm.Bag) (swarm.Event[M, T], error) {
 	evt, err := a.codec.Decode(bag)
 	if err != nil {
 		slog.Debug("router failed to decode event",
@@ -76,15 +129,75 @@ func (a evtRouter[M, T]) Route(ctx context.Context, bag swarm.Bag) error {
 			slog.Any("bag", bag),
 			slog.Any("err", err),
 		)
-		return swarm.ErrDecoder.With(err)
+		return evt, swarm.ErrDecoder.With(err)
+	}
+
+	if a.isIsolated(evt) {
+		return evt, swarm.ErrIsolated.With(nil, bag.Category)
+	}
+
+	if a.isExpired(evt) {
+		return evt, swarm.ErrExpired.With(nil, bag.Category)
+	}
+
+	return swarm.ToEvent(bag, evt), nil
+}
+
+func (a evtRouter[M, T]) isExpired(evt swarm.Event[M, T]) bool {
+	if a.maxAge == 0 || a.created == nil || evt.Meta == nil {
+		return false
+	}
+
+	created := a.created.Get(evt.Meta)
+	if created.IsZero() {
+		return false
+	}
+
+	return time.Since(created) > a.maxAge
+}
+
+// event is isolated from the router if it belongs to other realm or
+// it targets other agent. Events without realm or target are not isolated.
+func (a evtRouter[M, T]) isIsolated(evt swarm.Event[M, T]) bool {
+	if evt.Meta == nil {
+		return false
+	}
+
+	if a.realms != nil && a.realm != nil {
+		realm := a.realm.Get(evt.Meta)
+		if _, has := a.realms[realm]; realm != "" && !has {
+			return true
+		}
 	}
 
-	evt = swarm.ToEvent(bag, evt)
+	if a.agent != "" && a.target != nil {
+		target := a.target.Get(evt.Meta)
+		if target != "" && target != a.agent {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Router of union, it sends messages of multiple categories to the same channel
+type unionRouter[T any] struct {
+	ch     chan swarm.Msg[any]
+	decode func(swarm.Bag) (T, error)
+}
+
+func (a unionRouter[T]) Route(ctx context.Context, bag swarm.Bag) error {
+	obj, err := a.decode(bag)
+	if err != nil {
+		return err
+	}
+
+	msg := swarm.ToMsg[any](bag, obj)
 
 	select {
 	case <-ctx.Done():
 		return swarm.ErrRouting.With(nil, bag.Category)
-	case a.ch <- evt:
+	case a.ch <- msg:
 		return nil
 	}
 }
@@ -10,7 +10,10 @@ package kernel
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/fogfish/it/v2"
 	"github.com/fogfish/swarm"
@@ -35,6 +38,7 @@ func TestEvtRoute(t *testing.T) {
 	r := newEvtRouter(
 		make(chan E, 1),
 		encoding.ForEvent[E]("realm", "agent"),
+		swarm.NewConfig(),
 	)
 
 	r.Route(context.Background(), swarm.Bag{Object: []byte(`{"data": "1"}`)})
@@ -42,3 +46,90 @@ func TestEvtRoute(t *testing.T) {
 		it.Equal(*(<-r.ch).Data, `1`),
 	)
 }
+
+func TestEvtRouteExpired(t *testing.T) {
+	type E = swarm.Event[swarm.Meta, string]
+
+	cfg := swarm.NewConfig()
+	cfg.MaxEventAge = time.Minute
+
+	r := newEvtRouter(
+		make(chan E, 1),
+		encoding.ForEvent[E]("realm", "agent"),
+		cfg,
+	)
+
+	t.Run("Fresh", func(t *testing.T) {
+		created := time.Now().Add(-time.Second).Format(time.RFC3339)
+		err := r.Route(context.Background(),
+			swarm.Bag{Object: fmt.Appendf(nil, `{"meta": {"created": "%s"}, "data": "1"}`, created)},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*(<-r.ch).Data, `1`),
+		)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		created := time.Now().Add(-time.Hour).Format(time.RFC3339)
+		err := r.Route(context.Background(),
+			swarm.Bag{Object: fmt.Appendf(nil, `{"meta": {"created": "%s"}, "data": "1"}`, created)},
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, swarm.ErrExpired)),
+			it.Equal(len(r.ch), 0),
+		)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		err := r.Route(context.Background(), swarm.Bag{Object: []byte(`{"data": "1"}`)})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(*(<-r.ch).Data, `1`),
+		)
+	})
+}
+
+func TestEvtRouteIsolated(t *testing.T) {
+	type E = swarm.Event[swarm.Meta, string]
+
+	cfg := swarm.NewConfig()
+	cfg.Realm = "blue"
+	cfg.Agent = "agent"
+	cfg.AcceptRealms = []string{"canary"}
+
+	r := newEvtRouter(
+		make(chan E, 1),
+		encoding.ForEvent[E]("realm", "agent"),
+		cfg,
+	)
+
+	for _, tt := range []struct {
+		meta     string
+		isolated bool
+	}{
+		{meta: `{}`, isolated: false},
+		{meta: `{"realm": "blue"}`, isolated: false},
+		{meta: `{"realm": "canary"}`, isolated: false},
+		{meta: `{"realm": "green"}`, isolated: true},
+		{meta: `{"target": "agent"}`, isolated: false},
+		{meta: `{"target": "other"}`, isolated: true},
+		{meta: `{"realm": "blue", "target": "other"}`, isolated: true},
+	} {
+		t.Run(tt.meta, func(t *testing.T) {
+			err := r.Route(context.Background(),
+				swarm.Bag{Object: fmt.Appendf(nil, `{"meta": %s, "data": "1"}`, tt.meta)},
+			)
+			if tt.isolated {
+				it.Then(t).Should(
+					it.True(errors.Is(err, swarm.ErrIsolated)),
+				)
+			} else {
+				it.Then(t).Should(
+					it.Nil(err),
+					it.Equal(*(<-r.ch).Data, `1`),
+				)
+			}
+		})
+	}
+}
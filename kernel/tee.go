@@ -0,0 +1,141 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+)
+
+const ErrTeeTarget = faults.Safe1[int]("tee target %d has failed")
+
+// TeePolicy defines semantic of fan-out, how many targets must accept the message.
+type TeePolicy int
+
+const (
+	// All targets must accept the message
+	TeeAll TeePolicy = iota
+	// The message is accepted regardless of failures, failures are reported only
+	TeeBestEffort
+	// Majority of targets must accept the message
+	TeeQuorum
+)
+
+// required number of successful targets
+func (p TeePolicy) required(n int) int {
+	switch p {
+	case TeeBestEffort:
+		return 0
+	case TeeQuorum:
+		return n/2 + 1
+	default:
+		return n
+	}
+}
+
+type errTee struct {
+	accepted int
+	required int
+	err      error
+}
+
+func (err errTee) Error() string {
+	return fmt.Sprintf("tee accepted by %d targets, required %d: %s", err.accepted, err.required, err.err)
+}
+
+func (err errTee) Unwrap() error { return err.err }
+
+// The error is not retryable, retry of the fan-out duplicates messages
+// at targets that have accepted them. Tee retries each target individually.
+func (err errTee) NotRetryable() bool { return true }
+
+// Tee is fan-out emitter, it writes each message to several underlying emitters.
+// It is useful during migrations when same messages are published to multiple brokers.
+//
+//	tee := kernel.NewTee(cfg, kernel.TeeAll,
+//		sqs.Must(sqs.Emitter().Build(queue)).Emitter,
+//		eventbridge.Must(eventbridge.Emitter().Build(bus)).Emitter,
+//	)
+//	q := kernel.NewEmitter(tee, cfg)
+type Tee struct {
+	config  swarm.Config
+	policy  TeePolicy
+	targets []Emitter
+}
+
+func NewTee(config swarm.Config, policy TeePolicy, targets ...Emitter) *Tee {
+	return &Tee{
+		config:  config,
+		policy:  policy,
+		targets: targets,
+	}
+}
+
+func (t *Tee) Enq(ctx context.Context, bag swarm.Bag) error {
+	errs := make([]error, len(t.targets))
+
+	var wg sync.WaitGroup
+	for i, target := range t.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := t.config.Backoff.Retry(func() error {
+				return target.Enq(ctx, bag)
+			})
+			if err != nil {
+				errs[i] = ErrTeeTarget.With(err, i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	accepted := 0
+	for i, err := range errs {
+		if err == nil {
+			accepted++
+			continue
+		}
+
+		slog.Debug("tee failed to send message",
+			slog.Any("cat", bag.Category),
+			slog.Int("target", i),
+			slog.Any("err", err),
+		)
+		if t.config.StdErr != nil {
+			t.config.StdErr <- swarm.ErrEnqueue.With(err)
+		}
+	}
+
+	required := t.policy.required(len(t.targets))
+	if accepted < required {
+		return errTee{
+			accepted: accepted,
+			required: required,
+			err:      errors.Join(errs...),
+		}
+	}
+
+	return nil
+}
+
+// Close all targets
+func (t *Tee) Close() error {
+	errs := make([]error, 0, len(t.targets))
+	for _, target := range t.targets {
+		errs = append(errs, target.Close())
+	}
+
+	return errors.Join(errs...)
+}
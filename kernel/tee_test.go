@@ -0,0 +1,104 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kernel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel/encoding"
+)
+
+type failEmitter struct{}
+
+func (failEmitter) Enq(context.Context, swarm.Bag) error { return errors.New("failed") }
+func (failEmitter) Close() error                         { return nil }
+
+func TestTee(t *testing.T) {
+	bag := swarm.Bag{Category: "string", Object: []byte(`"1"`)}
+
+	t.Run("All", func(t *testing.T) {
+		cfg := newConfig()
+		a, b := newMockEmitter(cfg), newMockEmitter(cfg)
+		tee := NewTee(cfg.kernel, TeeAll, a, b)
+
+		it.Then(t).Should(
+			it.Nil(tee.Enq(context.Background(), bag)),
+			it.Equal(<-a.val, `"1"`),
+			it.Equal(<-b.val, `"1"`),
+		)
+	})
+
+	t.Run("All.Failed", func(t *testing.T) {
+		stderr := make(chan error, 1)
+		cfg := newConfig()
+		cfg.kernel.StdErr = stderr
+		a := newMockEmitter(cfg)
+		tee := NewTee(cfg.kernel, TeeAll, a, failEmitter{})
+
+		err := tee.Enq(context.Background(), bag)
+		it.Then(t).Should(
+			it.True(errors.Is(err, ErrTeeTarget)),
+			it.True(errors.Is(<-stderr, ErrTeeTarget)),
+			it.Equal(<-a.val, `"1"`),
+		)
+
+		var e interface{ NotRetryable() bool }
+		it.Then(t).Should(
+			it.True(errors.As(err, &e) && e.NotRetryable()),
+		)
+	})
+
+	t.Run("BestEffort", func(t *testing.T) {
+		stderr := make(chan error, 2)
+		cfg := newConfig()
+		cfg.kernel.StdErr = stderr
+		tee := NewTee(cfg.kernel, TeeBestEffort, failEmitter{}, failEmitter{})
+
+		it.Then(t).Should(
+			it.Nil(tee.Enq(context.Background(), bag)),
+			it.True(errors.Is(<-stderr, ErrTeeTarget)),
+			it.True(errors.Is(<-stderr, ErrTeeTarget)),
+		)
+	})
+
+	t.Run("Quorum", func(t *testing.T) {
+		cfg := newConfig()
+		a, b := newMockEmitter(cfg), newMockEmitter(cfg)
+
+		tee := NewTee(cfg.kernel, TeeQuorum, a, b, failEmitter{})
+		it.Then(t).Should(
+			it.Nil(tee.Enq(context.Background(), bag)),
+		)
+
+		tee = NewTee(cfg.kernel, TeeQuorum, a, failEmitter{}, failEmitter{})
+		it.Then(t).Should(
+			it.True(errors.Is(tee.Enq(context.Background(), bag), ErrTeeTarget)),
+		)
+	})
+
+	t.Run("Kernel", func(t *testing.T) {
+		cfg := newConfig()
+		a, b := newMockEmitter(cfg), newMockEmitter(cfg)
+		k := NewEmitter(NewTee(cfg.kernel, TeeAll, a, b), cfg.kernel)
+
+		snd, _ := EmitChan(k, encoding.ForTyped[string]())
+		snd <- "1"
+
+		it.Then(t).Should(
+			it.Equal(<-a.val, `"1"`),
+			it.Equal(<-b.val, `"1"`),
+		)
+
+		k.Close()
+	})
+}
@@ -0,0 +1,61 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package kernel
+
+import (
+	"strings"
+
+	"github.com/fogfish/swarm"
+)
+
+// Case of union, it binds the category with the router, which decodes
+// messages of the category into the union's channel.
+type Case func(k *ListenerIO, ch chan swarm.Msg[any]) (string, Router)
+
+// Case of union for messages of type T
+func CaseOf[T any](codec Decoder[T]) Case {
+	return func(k *ListenerIO, ch chan swarm.Msg[any]) (string, Router) {
+		return codec.Category(), unionRouter[T]{
+			ch:     ch,
+			decode: newMsgRouter[T](nil, codec).decode,
+		}
+	}
+}
+
+// Case of union for events of type T, the element of the union is [swarm.Event].
+func CaseOfEvent[E swarm.Event[M, T], M, T any](codec Decoder[swarm.Event[M, T]]) Case {
+	return func(k *ListenerIO, ch chan swarm.Msg[any]) (string, Router) {
+		return codec.Category(), unionRouter[swarm.Event[M, T]]{
+			ch:     ch,
+			decode: newEvtRouter(nil, codec, k.Config).decode,
+		}
+	}
+}
+
+// RecvUnion creates pair of channels within kernel to receive messages of
+// multiple categories. Messages are delivered in the order of the broker
+// within the poll batch. Use type switch on [swarm.Msg] Object to dispatch them.
+func RecvUnion(k *ListenerIO, cases ...Case) (<-chan swarm.Msg[any], chan<- swarm.Msg[any]) {
+	rcv := make(chan swarm.Msg[any], k.Config.CapRcv)
+	ack := make(chan swarm.Msg[any], k.Config.CapAck)
+
+	cats := make([]string, len(cases))
+
+	k.RWMutex.Lock()
+	for i, f := range cases {
+		cat, router := f(k, rcv)
+		k.router[cat] = router
+		cats[i] = cat
+	}
+	k.RWMutex.Unlock()
+
+	recvAck(k, strings.Join(cats, "|"), ack)
+
+	return rcv, ack
+}
@@ -121,6 +121,40 @@ func TestDequeueBytes(t *testing.T) {
 	)
 }
 
+func TestDequeueUnion(t *testing.T) {
+	cfg := swarm.NewConfig()
+	cfg.PollFrequency = 1 * time.Millisecond
+
+	user := User{ID: "id", Text: "user"}
+
+	k := kernel.NewListener(mockCathode("User", user), cfg)
+	go func() {
+		time.Sleep(yield_before_close)
+		k.Close()
+	}()
+
+	var msg swarm.Msg[any]
+	rcv, ack := dequeue.Union(k,
+		dequeue.Case[User](),
+		dequeue.CaseEvent[swarm.Event[swarm.Meta, string]](),
+	)
+
+	go func() {
+		msg = <-rcv
+		ack <- msg
+	}()
+	k.Await()
+
+	obj, ok := msg.Object.(User)
+	it.Then(t).Should(
+		it.True(ok),
+		it.Equal(msg.Category, "User"),
+		it.Equal(msg.Digest, "1"),
+		it.Equal(obj.ID, "id"),
+		it.Equal(obj.Text, "user"),
+	)
+}
+
 //------------------------------------------------------------------------------
 
 type cathode[T any] struct {
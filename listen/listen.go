@@ -42,3 +42,45 @@ func Event[E swarm.Event[M, T], M, T any](q *kernel.ListenerIO, codec ...kernel.
 func Bytes(q *kernel.ListenerIO, codec kernel.Decoder[[]byte]) (<-chan swarm.Msg[[]byte], chan<- swarm.Msg[[]byte]) {
 	return kernel.RecvChan(q, codec)
 }
+
+// Creates pair of channels to receive and acknowledge messages of multiple
+// categories. Messages are delivered in the order of the broker, use type switch
+// to dispatch them:
+//
+//	rcv, ack := listen.Union(q, listen.Case[User](), listen.CaseEvent[Note]())
+//	for msg := range rcv {
+//		switch msg.Object.(type) {
+//		case User:
+//		case Note:
+//		}
+//		ack <- msg
+//	}
+func Union(q *kernel.ListenerIO, cases ...kernel.Case) (<-chan swarm.Msg[any], chan<- swarm.Msg[any]) {
+	return kernel.RecvUnion(q, cases...)
+}
+
+// Case of union for messages of type T
+func Case[T any](codec ...kernel.Decoder[T]) kernel.Case {
+	var c kernel.Decoder[T]
+	if len(codec) == 0 {
+		c = encoding.ForTyped[T]()
+	} else {
+		c = codec[0]
+	}
+
+	return kernel.CaseOf(c)
+}
+
+// Case of union for events of type T
+func CaseEvent[E swarm.Event[M, T], M, T any](codec ...kernel.Decoder[swarm.Event[M, T]]) kernel.Case {
+	return func(q *kernel.ListenerIO, ch chan swarm.Msg[any]) (string, kernel.Router) {
+		var c kernel.Decoder[swarm.Event[M, T]]
+		if len(codec) == 0 {
+			c = encoding.ForEvent[E](q.Config.Realm, q.Config.Agent)
+		} else {
+			c = codec[0]
+		}
+
+		return kernel.CaseOfEvent[E](c)(q, ch)
+	}
+}
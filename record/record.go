@@ -0,0 +1,185 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+// Package record captures message traffic into JSON Lines recording and
+// replays it back into any listener. It helps to reproduce production issues
+// by re-sending exact payloads.
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fogfish/faults"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+)
+
+const (
+	ErrRecord = faults.Type("recorder failed to write message")
+	ErrReplay = faults.Type("replay failed to read message")
+)
+
+// Direction of the recorded message
+const (
+	Enqueue = "enq"
+	Dequeue = "deq"
+)
+
+// Record is a line of recording. JSON payload is embedded as is,
+// other payloads are embedded as base64 octets.
+type Record struct {
+	Time     time.Time       `json:"time"`
+	IO       string          `json:"io"`
+	Category string          `json:"category"`
+	Digest   swarm.Digest    `json:"digest,omitempty"`
+	Expires  *time.Time      `json:"expires,omitempty"`
+	Object   json.RawMessage `json:"object,omitempty"`
+	Octets   []byte          `json:"octets,omitempty"`
+}
+
+func toRecord(io string, bag swarm.Bag) Record {
+	rec := Record{
+		Time:     time.Now(),
+		IO:       io,
+		Category: bag.Category,
+		Digest:   bag.Digest,
+	}
+
+	if !bag.Expires.IsZero() {
+		rec.Expires = &bag.Expires
+	}
+
+	// Only compact JSON is embedded as is, it guarantees exact payload on replay
+	var buf bytes.Buffer
+	if json.Compact(&buf, bag.Object) == nil && bytes.Equal(buf.Bytes(), bag.Object) {
+		rec.Object = bag.Object
+	} else {
+		rec.Octets = bag.Object
+	}
+
+	return rec
+}
+
+// Bag of the recorded message
+func (rec Record) Bag() swarm.Bag {
+	bag := swarm.Bag{
+		Category: rec.Category,
+		Digest:   rec.Digest,
+		Object:   rec.Object,
+	}
+
+	if rec.Octets != nil {
+		bag.Object = rec.Octets
+	}
+
+	if rec.Expires != nil {
+		bag.Expires = *rec.Expires
+	}
+
+	return bag
+}
+
+// Recorder appends records to the writer, it is safe for concurrent use.
+type Recorder struct {
+	sync.Mutex
+	w *json.Encoder
+}
+
+// Creates recorder that writes JSON Lines to the writer
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: json.NewEncoder(w)}
+}
+
+// recording is not a part of the message flow, failures are logged only
+func (r *Recorder) write(io string, bag swarm.Bag) {
+	r.Lock()
+	defer r.Unlock()
+
+	if err := r.w.Encode(toRecord(io, bag)); err != nil {
+		slog.Error("recorder failed to write message",
+			slog.Any("cat", bag.Category),
+			slog.Any("err", ErrRecord.With(err)),
+		)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type emitter struct {
+	kernel.Emitter
+	recorder *Recorder
+}
+
+// Emitter wraps the emitter kernel with recorder, messages are recorded
+// after they are successfully enqueued to the broker.
+// It must be called before any channel is created for the kernel.
+//
+//	f, _ := os.Create("traffic.jsonl")
+//	q := record.Emitter(sqs.Must(sqs.Emitter().Build(queue)), record.NewRecorder(f))
+//	enq, dlq := emit.Typed[User](q)
+func Emitter(q *kernel.EmitterIO, recorder *Recorder) *kernel.EmitterIO {
+	q.Emitter = &emitter{
+		Emitter:  q.Emitter,
+		recorder: recorder,
+	}
+
+	return q
+}
+
+func (e *emitter) Enq(ctx context.Context, bag swarm.Bag) error {
+	if err := e.Emitter.Enq(ctx, bag); err != nil {
+		return err
+	}
+
+	e.recorder.write(Enqueue, bag)
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type listener struct {
+	kernel.Listener
+	recorder *Recorder
+}
+
+// Listener wraps the listener kernel with recorder, messages are recorded
+// as they are received from the broker.
+// It must be called before the kernel is awaited.
+//
+//	f, _ := os.Create("traffic.jsonl")
+//	q := record.Listener(sqs.Must(sqs.Listener().Build(queue)), record.NewRecorder(f))
+//	deq, ack := listen.Typed[User](q)
+func Listener(q *kernel.ListenerIO, recorder *Recorder) *kernel.ListenerIO {
+	l := &listener{
+		Listener: q.Listener,
+		recorder: recorder,
+	}
+
+	q.Listener = kernel.Decorate(q.Listener, l)
+
+	return q
+}
+
+func (l *listener) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	seq, err := l.Listener.Ask(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bag := range seq {
+		l.recorder.write(Dequeue, bag)
+	}
+
+	return seq, nil
+}
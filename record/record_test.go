@@ -0,0 +1,201 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package record_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fogfish/it/v2"
+	"github.com/fogfish/swarm"
+	"github.com/fogfish/swarm/kernel"
+	"github.com/fogfish/swarm/listen"
+	"github.com/fogfish/swarm/record"
+)
+
+type User struct {
+	ID string `json:"id"`
+}
+
+func TestRecord(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Emitter", func(t *testing.T) {
+		var buf bytes.Buffer
+		q := record.Emitter(kernel.NewEmitter(&mockBroker{}, swarm.Config{}), record.NewRecorder(&buf))
+
+		err := q.Emitter.Enq(ctx, swarm.Bag{Category: "User", Object: []byte(`{"id":"1"}`)})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Json(json.RawMessage(buf.Bytes())).Equiv(`
+				{"time": "_", "io": "enq", "category": "User", "object": {"id":"1"}}
+			`),
+		)
+	})
+
+	t.Run("Listener", func(t *testing.T) {
+		var buf bytes.Buffer
+		mock := &mockBroker{bag: swarm.Bag{Category: "Bytes", Digest: "1", Object: []byte("\x00\x01")}}
+		q := record.Listener(kernel.NewListener(mock, swarm.Config{}), record.NewRecorder(&buf))
+
+		seq, err := q.Listener.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equiv(seq[0].Object, []byte("\x00\x01")),
+			it.Json(json.RawMessage(buf.Bytes())).Equiv(`
+				{"time": "_", "io": "deq", "category": "Bytes", "digest": "1", "octets": "AAE="}
+			`),
+		)
+	})
+
+	t.Run("Listener.Spawner", func(t *testing.T) {
+		mock := &mockSpawner{run: make(chan struct{})}
+		q := record.Listener(kernel.NewListener(mock, swarm.Config{}), record.NewRecorder(io.Discard))
+
+		spawner, ok := q.Listener.(interface{ Run(context.Context) })
+		it.Then(t).Should(it.True(ok))
+
+		go spawner.Run(ctx)
+		<-mock.run
+	})
+
+	t.Run("Exact", func(t *testing.T) {
+		var buf bytes.Buffer
+		q := record.Emitter(kernel.NewEmitter(&mockBroker{}, swarm.Config{}), record.NewRecorder(&buf))
+		obj := []byte(`{ "id": "1" }`)
+
+		err := q.Emitter.Enq(ctx, swarm.Bag{Category: "User", Object: obj})
+		it.Then(t).Should(it.Nil(err))
+
+		seq, err := record.NewReplay(&buf, record.Enqueue, 0).Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equiv(seq[0].Object, obj),
+		)
+	})
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	recording := `
+		{"time": "2025-01-01T00:00:00Z", "io": "enq", "category": "User", "digest": "1", "object": {"id":"1"}}
+		{"time": "2025-01-01T00:00:01Z", "io": "enq", "category": "User", "digest": "2", "object": {"id":"2"}}
+	`
+
+	t.Run("Pace", func(t *testing.T) {
+		replay := record.NewReplay(strings.NewReader(recording), record.Enqueue, 10)
+
+		a, err := replay.Ask(ctx)
+		it.Then(t).Should(it.Nil(err))
+
+		t0 := time.Now()
+		b, err := replay.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a[0].Digest, "1"),
+			it.Equal(b[0].Digest, "2"),
+			it.True(time.Since(t0) >= 90*time.Millisecond),
+		)
+
+		seq, err := replay.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).BeEmpty(),
+		)
+		<-replay.Done()
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		replay := record.NewReplay(strings.NewReader(`{"time": 1}`+recording), record.Enqueue, 0)
+
+		a, err := replay.Ask(ctx)
+		it.Then(t).Should(it.Nil(err))
+
+		b, err := replay.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a[0].Digest, "1"),
+			it.Equal(b[0].Digest, "2"),
+		)
+	})
+
+	t.Run("Direction", func(t *testing.T) {
+		replay := record.NewReplay(strings.NewReader(`
+			{"time": "2025-01-01T00:00:00Z", "io": "enq", "category": "User", "digest": "1", "object": {"id":"1"}}
+			{"time": "2025-01-01T00:00:01Z", "io": "deq", "category": "User", "digest": "2", "object": {"id":"1"}}
+		`), record.Dequeue, 0)
+
+		a, err := replay.Ask(ctx)
+		it.Then(t).Should(it.Nil(err))
+
+		b, err := replay.Ask(ctx)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a[0].Digest, "2"),
+			it.Seq(b).BeEmpty(),
+		)
+		<-replay.Done()
+	})
+
+	t.Run("Kernel", func(t *testing.T) {
+		cfg := swarm.NewConfig()
+		cfg.PollFrequency = 1 * time.Millisecond
+
+		q := kernel.NewListener(record.NewReplay(strings.NewReader(recording), record.Enqueue, 0), cfg)
+		rcv, ack := listen.Typed[User](q)
+		go q.Await()
+
+		a := <-rcv
+		ack <- a
+		b := <-rcv
+		ack <- b
+
+		it.Then(t).Should(
+			it.Equal(a.Object.ID, "1"),
+			it.Equal(b.Object.ID, "2"),
+		)
+
+		q.Close()
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// loopback broker, the listener receives the last emitted bag
+type mockBroker struct {
+	bag swarm.Bag
+}
+
+func (m *mockBroker) Enq(ctx context.Context, bag swarm.Bag) error {
+	m.bag = bag
+	return nil
+}
+
+func (m *mockBroker) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	return []swarm.Bag{m.bag}, nil
+}
+
+func (m *mockBroker) Ack(ctx context.Context, digest swarm.Digest) error { return nil }
+
+func (m *mockBroker) Err(ctx context.Context, digest swarm.Digest, err error) error { return nil }
+
+func (m *mockBroker) Close() error { return nil }
+
+// serverless broker, it is spawned by the kernel
+type mockSpawner struct {
+	mockBroker
+	run chan struct{}
+}
+
+func (m *mockSpawner) Run(ctx context.Context) { close(m.run) }
@@ -0,0 +1,131 @@
+//
+// Copyright (C) 2021 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the Apache License Version 2.0. See the LICENSE file for details.
+// https://github.com/fogfish/swarm
+//
+
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fogfish/swarm"
+)
+
+// Replay is the listener that feeds the recording back to the kernel.
+// Only records of the given direction (Enqueue or Dequeue) are replayed.
+// Messages are delivered at the pace of the recording scaled by the speed
+// factor (e.g. 1 is the original pace, 10 is ten times faster),
+// zero speed delivers messages without delays. Malformed lines of
+// the recording are skipped.
+//
+//	f, _ := os.Open("traffic.jsonl")
+//	q := kernel.NewListener(record.NewReplay(f, record.Dequeue, 1), swarm.NewConfig())
+//	deq, ack := listen.Typed[User](q)
+type Replay struct {
+	sync.Mutex
+	r     *bufio.Reader
+	io    string
+	speed float64
+
+	// wall clock and recording time of the first message
+	start  time.Time
+	origin time.Time
+
+	done chan struct{}
+}
+
+// Creates replay of the JSON Lines recording
+func NewReplay(r io.Reader, io string, speed float64) *Replay {
+	return &Replay{
+		r:     bufio.NewReader(r),
+		io:    io,
+		speed: speed,
+		done:  make(chan struct{}),
+	}
+}
+
+// Done is closed when the recording is exhausted
+func (r *Replay) Done() <-chan struct{} { return r.done }
+
+func (r *Replay) Ask(ctx context.Context) ([]swarm.Bag, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.r == nil {
+		return nil, nil
+	}
+
+	rec, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if rec == nil {
+		r.r = nil
+		close(r.done)
+		return nil, nil
+	}
+
+	if r.start.IsZero() {
+		r.start, r.origin = time.Now(), rec.Time
+	}
+
+	if r.speed > 0 {
+		due := r.start.Add(time.Duration(float64(rec.Time.Sub(r.origin)) / r.speed))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Until(due)):
+		}
+	}
+
+	return []swarm.Bag{rec.Bag()}, nil
+}
+
+// reads next record of the replayed direction, nil at the end of recording
+func (r *Replay) next() (*Record, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, ErrReplay.With(err)
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return nil, nil
+			}
+			continue
+		}
+
+		var rec Record
+		if exx := json.Unmarshal(line, &rec); exx != nil {
+			slog.Warn("replay skipped malformed record",
+				slog.String("line", string(line)),
+				slog.Any("err", ErrReplay.With(exx)),
+			)
+			continue
+		}
+
+		if rec.IO == r.io {
+			return &rec, nil
+		}
+	}
+}
+
+func (r *Replay) Ack(ctx context.Context, digest swarm.Digest) error { return nil }
+
+func (r *Replay) Err(ctx context.Context, digest swarm.Digest, err error) error { return nil }
+
+func (r *Replay) Close() error { return nil }
@@ -8,4 +8,4 @@
 
 package swarm
 
-const Version = "v0.24.1"
+const Version = "v0.25.0"